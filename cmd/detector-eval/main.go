@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"lol-auto-accept/internal/corpus"
+	"lol-auto-accept/internal/detector"
+	"lol-auto-accept/internal/synth"
+)
+
+// ラベル付きコーパスで検出器を評価し、基準より悪化していれば終了コード1で終了する
+// リポジトリのルートで実行すること（テンプレートを resources/ から読み込むため）
+func main() {
+	corpusDir := flag.String("corpus", "testdata/corpus", "コーパスのディレクトリ")
+	baselinePath := flag.String("baseline", "", "基準となる評価結果 (既定: <corpus>/baseline.json)")
+	updateBaseline := flag.Bool("update-baseline", false, "今回の評価結果を基準として保存する")
+	synthetic := flag.Int("synthetic", 0, "評価に加える合成レディチェック画面の枚数")
+	negatives := flag.Int("negatives", 0, "評価に加えるテンプレートなしの合成フレームの枚数（誤検出の確認用）")
+	seed := flag.Int64("seed", 1, "合成フレームの乱数シード")
+	writeDir := flag.String("write", "", "合成フレームをコーパス形式で保存するディレクトリ")
+	flag.Parse()
+
+	if *baselinePath == "" {
+		*baselinePath = filepath.Join(*corpusDir, "baseline.json")
+	}
+
+	cases, err := corpus.Load(*corpusDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 合成フレームは別に評価して表示するだけで、基準との比較や保存には含めない
+	var generated []corpus.Case
+	if *synthetic > 0 || *negatives > 0 {
+		generated, err = generate(*synthetic, *negatives, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *writeDir != "" {
+			for _, c := range generated {
+				if err := corpus.Save(*writeDir, c); err != nil {
+					log.Fatal(err)
+				}
+			}
+			fmt.Printf("合成フレームを保存しました: %s (%d 枚)\n", *writeDir, len(generated))
+		}
+	}
+
+	if len(cases) == 0 && (len(generated) == 0 || *updateBaseline) {
+		log.Fatalf("コーパスにフレームがありません: %s", *corpusDir)
+	}
+
+	d := detector.NewImageDetector()
+	if err := d.LoadTemplates(); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(generated) > 0 {
+		fmt.Println("[合成フレーム]")
+		printReport(corpus.Evaluate(d, generated))
+		if len(cases) == 0 {
+			return
+		}
+		fmt.Println("[コーパス]")
+	}
+
+	report := corpus.Evaluate(d, cases)
+	printReport(report)
+
+	if *updateBaseline {
+		if err := report.Save(*baselinePath); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("基準を更新しました: %s\n", *baselinePath)
+		return
+	}
+
+	baseline, err := corpus.LoadReport(*baselinePath)
+	if os.IsNotExist(err) {
+		fmt.Println("基準がないため比較をスキップしました (-update-baseline で作成できます)")
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if regressions := report.Regressions(baseline); len(regressions) > 0 {
+		fmt.Println("基準より悪化しました:")
+		for _, r := range regressions {
+			fmt.Printf("  %s\n", r)
+		}
+		os.Exit(1)
+	}
+	fmt.Println("基準との比較: 悪化なし")
+}
+
+func printReport(report corpus.Report) {
+	fmt.Printf("フレーム数: %d\n", report.Cases)
+	fmt.Printf("マッチング画面: precision %.3f, recall %.3f (%+v)\n",
+		report.Matching.Precision(), report.Matching.Recall(), report.Matching)
+	fmt.Printf("承認ボタン:     precision %.3f, recall %.3f (%+v)\n",
+		report.Accept.Precision(), report.Accept.Recall(), report.Accept)
+	for _, f := range report.Failures {
+		fmt.Printf("  NG %s [%s] %s\n", f.Case, f.Kind, f.Detail)
+	}
+}
+
+// 合成フレームの生成（同じシードからは同じフレームが得られる）
+func generate(readyChecks, negatives int, seed int64) ([]corpus.Case, error) {
+	g, err := synth.LoadGenerator(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]corpus.Case, 0, readyChecks+negatives)
+	for i := 0; i < readyChecks; i++ {
+		cases = append(cases, g.RandomReadyCheck(fmt.Sprintf("synthetic_ready_%03d", i)))
+	}
+	for i := 0; i < negatives; i++ {
+		cases = append(cases, g.RandomNegative(fmt.Sprintf("synthetic_negative_%03d", i)))
+	}
+	return cases, nil
+}
@@ -3,14 +3,14 @@ module lol-auto-accept
 go 1.21
 
 require (
+	github.com/gen2brain/shm v0.0.0-20230802011745-f2460f5984f7
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/websocket v1.5.0
+	github.com/jezek/xgb v1.1.0
 	github.com/kbinani/screenshot v0.0.0-20230812210009-b87d31814237
 )
 
 require (
-	github.com/gen2brain/shm v0.0.0-20230802011745-f2460f5984f7 // indirect
-	github.com/jezek/xgb v1.1.0 // indirect
 	github.com/lxn/win v0.0.0-20210218163916-a377121e959e // indirect
 	golang.org/x/sys v0.30.0 // indirect
 )
@@ -2,40 +2,220 @@ package app
 
 import (
 	"fmt"
+	"image"
+	"log/slog"
 	"sync"
 	"time"
 
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/config"
 	"lol-auto-accept/internal/detector"
+	"lol-auto-accept/internal/history"
+	"lol-auto-accept/internal/logging"
+	"lol-auto-accept/internal/snapshot"
 	"lol-auto-accept/internal/system"
+	"lol-auto-accept/internal/templates"
 	"lol-auto-accept/internal/websocket"
 )
 
+// 何回に1回クライアント全体をキャプチャして位置を再特定するか
+const fullFrameInterval = 10
+
+// 統計情報を送信する間隔
+const statsInterval = 5 * time.Second
+
+// クリック後にマッチング画面の状態を確認するまでの待ち時間
+const postClickDelay = 5 * time.Second
+
+// 待機状況のログを送る間隔
+const (
+	waitingLogInterval   = 5 * time.Second
+	searchingLogInterval = 10 * time.Second
+)
+
 type App struct {
 	running         bool
 	waitingForMatch bool
 	autoWatching    bool
+	autoWatchGen    uint64
+	monitorGen      uint64
+	status          string
 	mutex           sync.RWMutex
+	lastStatsReport time.Time
+	lastCapture     time.Time
+	lastCaptureErr  error
+	templatesErr    error // 最後にテンプレートを読み込んだときのエラー
+	lastTick        time.Time
+	loopStarted     time.Time
 	
-	detector     *detector.ImageDetector
-	wsManager    *websocket.Manager
-	systemCtrl   *system.Controller
+	clock            clock.Clock
+	log              *slog.Logger
+	detectorLog      *slog.Logger
+	systemLog        *slog.Logger
+	logThrottle      *logThrottle
+	source           capture.Source
+	windowMode       bool
+	planner          *capture.RegionPlanner
+	scheduler        *pollScheduler
+	latestFrame      capture.LatestFrame
+	calibrationFrame capture.LatestFrame
+	templates        *templates.Store
+	activePack       string
+	configPath       string
+	snapshots        *snapshot.Writer
+	history          *history.Store
+	readyChecks      *readyCheckTracker
+	metrics          *appMetrics
+	detector         *detector.ImageDetector
+	wsManager        *websocket.Manager
+	systemCtrl       *system.Controller
+	inputSupported   bool   // 起動時に確認したマウス操作の可否（確認のたびに外部コマンドを実行しない）
+	lockfile         string // クライアントの lockfile（空の場合はAPIへの接続を確認しない）
 }
 
-func NewApp() *App {
-	return &App{
+func NewApp(cfg *config.Config) *App {
+	systemCtrl := system.NewController()
+	if cfg.DryRun {
+		systemCtrl = system.NewDryRunController()
+	}
+
+	var source capture.Source = capture.NewDisplaySource(clock.Real)
+	if cfg.Capture.WindowTitle != "" {
+		source = capture.NewWindowSource(cfg.Capture.WindowTitle, clock.Real)
+	}
+	return NewAppWith(cfg, source, systemCtrl, clock.Real)
+}
+
+// キャプチャ元・マウス操作・時計を指定して作成（シミュレーターとの統合テスト用）
+func NewAppWith(cfg *config.Config, source capture.Source, systemCtrl *system.Controller, clk clock.Clock) *App {
+	systemCtrl.SetClock(clk)
+	systemCtrl.SetMotion(system.MotionConfig{
+		Humanize: cfg.Mouse.Humanize,
+		Duration: time.Duration(cfg.Mouse.DurationMs) * time.Millisecond,
+		Easing:   cfg.Mouse.Easing,
+		Jitter:   cfg.Mouse.Jitter,
+	})
+
+	lockfile := cfg.Client.Lockfile
+	if lockfile == "" {
+		lockfile = defaultLockfile()
+	}
+
+	activePack := cfg.Templates.Active
+	if activePack == "" {
+		activePack = templates.DefaultPack
+	}
+
+	a := &App{
 		running:         false,
 		waitingForMatch: false,
 		autoWatching:    false,
+		status:          statusStopped,
+		clock:           clk,
+		log:             logging.For(logging.ComponentApp),
+		detectorLog:     logging.For(logging.ComponentDetector),
+		systemLog:       logging.For(logging.ComponentSystem),
+		logThrottle:     newLogThrottle(clk),
+		source:          source,
+		windowMode:      cfg.Capture.WindowTitle != "",
+		planner:         capture.NewRegionPlanner(fullFrameInterval),
+		templates:       templates.NewStore(cfg.Templates.Dir),
+		activePack:      activePack,
 		detector:        detector.NewImageDetector(),
 		wsManager:       websocket.NewManager(),
-		systemCtrl:      system.NewController(),
+		systemCtrl:      systemCtrl,
+		inputSupported:  systemCtrl.IsSystemSupported(),
+		lockfile:        lockfile,
+	}
+	logging.SetFeed(a.wsManager.SendLog)
+	if cfg.History.File != "" {
+		store, err := history.Open(cfg.History.File)
+		if err != nil {
+			a.log.Warn("履歴を読み込めないため、レディチェックの記録は保存しません", "error", err)
+		} else {
+			a.history = store
+		}
+	}
+	a.readyChecks = newReadyCheckTracker(clk, a.history, a.log)
+	a.metrics = newAppMetrics(a)
+	if cfg.Debug.Snapshots {
+		a.snapshots = snapshot.NewWriter(cfg.Debug.Dir, cfg.Debug.MaxSnapshots, clk)
 	}
+	a.scheduler = newPollScheduler(func(interval time.Duration, mode pollMode) {
+		a.wsManager.UpdateRate(interval.Milliseconds(), string(mode))
+	})
+	return a
 }
 
 func (a *App) GetWebSocketManager() *websocket.Manager {
 	return a.wsManager
 }
 
+func (a *App) GetStats() detector.Stats {
+	return a.detector.Stats()
+}
+
+// 統計情報を一定間隔でUIに送信
+func (a *App) reportStats() {
+	a.mutex.Lock()
+	if a.clock.Since(a.lastStatsReport) < statsInterval {
+		a.mutex.Unlock()
+		return
+	}
+	a.lastStatsReport = a.clock.Now()
+	a.mutex.Unlock()
+
+	stats := a.detector.Stats()
+	a.wsManager.UpdateStats(stats.FramesProcessed, stats.FramesSkipped)
+}
+
+// 現在のポーリング間隔とモード
+func (a *App) GetPollRate() (time.Duration, string) {
+	return a.scheduler.Interval(), string(a.scheduler.Mode())
+}
+
+// キャプチャ結果をスケジューラーに反映
+func (a *App) observeCapture(frame *capture.Frame, err error) {
+	a.recordCapture(err)
+	if err != nil {
+		a.metrics.captureErrors.Inc()
+		a.scheduler.CaptureFailed()
+		return
+	}
+	a.metrics.framesCaptured.Inc()
+	// ウィンドウ検出に対応していない環境や、ウィンドウ検出を使わない場合はクライアントがあるものとみなす
+	a.scheduler.CaptureSucceeded(!frame.ClientMissing)
+}
+
+// 直近にキャプチャしたフレーム（利用後に Release が必要、未取得の場合は nil）
+func (a *App) AcquireLatestFrame() *capture.Frame {
+	return a.latestFrame.Acquire()
+}
+
+// レディチェックの履歴（保存しない設定の場合は nil）
+func (a *App) GetHistory() *history.Store {
+	return a.history
+}
+
+// デバッグスナップショットの保存先（無効な場合は nil）
+func (a *App) GetSnapshotWriter() *snapshot.Writer {
+	return a.snapshots
+}
+
+// デバッグモード時のみ判定結果のスナップショットを保存
+func (a *App) saveSnapshot(frame *capture.Frame, trace *detector.Trace, decision string, score float64) {
+	if a.snapshots == nil {
+		return
+	}
+	id := a.snapshots.Save(frame, snapshot.Snapshot{Decision: decision, Score: score, Trace: trace})
+	a.detectorLog.Debug("デバッグスナップショットを保存しました", "id", id, "decision", decision)
+}
+
+func (a *App) IsDryRun() bool {
+	return a.systemCtrl.IsDryRun()
+}
+
 func (a *App) IsRunning() bool {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
@@ -72,205 +252,299 @@ func (a *App) SetAutoWatching(watching bool) {
 	a.autoWatching = watching
 }
 
-func (a *App) StartMonitoring() {
+// 自動監視を有効にして世代番号を返す（停止後すぐに再開しても古いループが残らないようにする）
+func (a *App) beginAutoWatch() uint64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.autoWatching = true
+	a.autoWatchGen++
+	return a.autoWatchGen
+}
+
+func (a *App) isAutoWatcher(gen uint64) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.autoWatching && a.autoWatchGen == gen
+}
+
+// 監視中でなければ監視を開始して世代番号を返す（同時に開始しても監視ループは1つだけにする）
+func (a *App) beginMonitoring() (uint64, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.running {
+		return 0, false
+	}
+	a.running = true
+	a.waitingForMatch = true // 最初はマッチング画面を待機
+	a.monitorGen++
+	return a.monitorGen, true
+}
+
+// 停止後すぐに再開した場合、待機中だった古いループは自分の世代でなくなったことで終了する
+func (a *App) isMonitor(gen uint64) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.running && a.monitorGen == gen
+}
+
+// 監視を開始する（テンプレートを読み込めない場合はエラー）
+func (a *App) StartMonitoring() error {
 	if a.IsRunning() {
-		return
+		return nil
 	}
 
 	// テンプレート画像の読み込み
-	if err := a.detector.LoadTemplates(); err != nil {
-		a.wsManager.SendLog(fmt.Sprintf("テンプレート読み込みエラー: %v", err))
-		return
+	if err := a.loadTemplates(); err != nil {
+		a.log.Error("テンプレート読み込みエラー", "pack", a.ActiveTemplatePack(), "error", err)
+		return err
 	}
 
-	a.SetRunning(true)
-	a.SetWaitingForMatch(true) // 最初はマッチング画面を待機
-	a.wsManager.UpdateStatus("マッチング画面待機中...")
-	a.wsManager.SendLog("自動監視を開始しました - マッチング画面を検出中")
+	a.markLoopStarted()
+	gen, ok := a.beginMonitoring()
+	if !ok {
+		return nil
+	}
+	a.publishState("マッチング画面待機中...")
+	a.log.Info("自動監視を開始しました - マッチング画面を検出中")
+	if a.IsDryRun() {
+		a.systemLog.Info("ドライランモード: クリックは実行されず記録のみ行います")
+	}
 
 	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
-		defer ticker.Stop()
-
-		for a.IsRunning() {
-			<-ticker.C
-			start := time.Now()
-			
-			// スクリーンショット取得
-			img, err := a.detector.CaptureScreen()
-			if err != nil {
-				continue
+		for a.isMonitor(gen) {
+			// 監視状態に応じた間隔で待機
+			a.clock.Sleep(a.scheduler.Interval())
+			if !a.isMonitor(gen) {
+				return
 			}
-
-			if a.IsWaitingForMatch() {
-				// マッチング画面を待機中
-				if a.detector.FastDetectMatchingScreen(img) {
-					a.wsManager.SendLog("マッチング画面を検出 - 承認ボタン監視を開始")
-					a.SetWaitingForMatch(false)
-					a.wsManager.UpdateStatus("承認ボタン監視中...")
-				} else {
-					// 5秒に1回マッチング待機状況をログ出力
-					if time.Now().Unix()%5 == 0 {
-						bounds := img.Bounds()
-						a.wsManager.SendLog(fmt.Sprintf("マッチング画面を待機中... (画面サイズ: %dx%d)", bounds.Dx(), bounds.Dy()))
-					}
-				}
-			} else {
-				// 承認ボタンを監視中
-				// まずマッチング画面がまだ存在するかチェック
-				if !a.detector.FastDetectMatchingScreen(img) {
-					// マッチング画面が検出されなくなった場合、監視を停止
-					a.wsManager.SendLog("マッチング画面が検出されなくなりました - 監視を自動停止します")
-					a.StopMonitoring()
-					return
-				}
-				
-				buttonPos := a.detector.FastDetectAcceptButton(img)
-				if buttonPos != nil {
-					elapsed := time.Since(start)
-					
-					// 詳細検証スコアを取得
-					verifyScore := a.detector.VerifyAcceptButton(img, buttonPos, 1.0)
-					a.wsManager.SendLog(fmt.Sprintf("承認ボタンを検出しました (位置: %d, %d, 検証スコア: %.3f, 検出時間: %v)", 
-						buttonPos.X, buttonPos.Y, verifyScore, elapsed))
-					
-					// より低い閾値でも許可（検証スコアが低くてもクリック）
-					if verifyScore > 0.2 {
-						if a.systemCtrl.ClickAcceptButton(buttonPos.X, buttonPos.Y) {
-							a.wsManager.SendLog("承認ボタンをクリックしました")
-							a.wsManager.SendLog("5秒待機後、マッチング画面の状態をチェックします")
-							// 5秒待機
-							time.Sleep(5 * time.Second)
-							// 5秒後にマッチング画面が検出されるかチェック
-							img2, err := a.detector.CaptureScreen()
-							if err == nil && !a.detector.FastDetectMatchingScreen(img2) {
-								a.wsManager.SendLog("マッチング画面が検出されなくなりました - 監視を自動停止します")
-								a.StopMonitoring()
-								return
-							} else {
-								a.wsManager.SendLog("マッチング画面が継続中 - 監視を継続します")
-							}
-						} else {
-							a.wsManager.SendLog("承認ボタンのクリックに失敗しました")
-						}
-					} else {
-						a.wsManager.SendLog(fmt.Sprintf("検証スコアが低いため、クリックをスキップしました (スコア: %.3f)", verifyScore))
-					}
-				} else {
-					// 10秒に1回承認ボタン検索状況をログ出力（頻度を上げる）
-					if time.Now().Unix()%10 == 0 {
-						elapsed := time.Since(start)
-						a.wsManager.SendLog(fmt.Sprintf("承認ボタンを検索中... (検索時間: %v)", elapsed))
-						// デバッグ: 検索エリアの情報も出力
-						bounds := img.Bounds()
-						a.wsManager.SendLog(fmt.Sprintf("検索エリア: 画面サイズ %dx%d, 中央下部を重点検索", bounds.Dx(), bounds.Dy()))
-					}
-				}
+			if !a.monitorTick(gen) {
+				return
 			}
 		}
 	}()
+	return nil
+}
+
+// 監視ループの1回分の処理（監視を終了する場合は false を返す）
+func (a *App) monitorTick(gen uint64) bool {
+	start := a.clock.Now()
+	
+	// スクリーンショット取得
+	// 検出に必要な領域だけを取得（一定回数ごとに全体を取得）
+	region := a.planner.Next(a.detector.ActiveRegions(a.IsWaitingForMatch())...)
+	frame, err := a.source.Capture(region)
+	a.observeCapture(frame, err)
+	if err != nil {
+		return true
+	}
+	defer frame.Release()
+	a.latestFrame.Set(frame)
+	img := frame.Image
+	a.reportStats()
+
+	if a.IsWaitingForMatch() {
+		// マッチング画面を待機中
+		if a.detectMatching(frame) {
+			a.wsManager.SendDetection(websocket.TargetMatching, 0, 0, 0, a.clock.Since(start).Milliseconds())
+			a.readyChecks.queueStarted()
+			a.detectorLog.Info("マッチング画面を検出 - 承認ボタン監視を開始", "elapsed", a.clock.Since(start))
+			a.SetWaitingForMatch(false)
+			a.scheduler.SetMode(pollModeMatching)
+			a.publishState("承認ボタン監視中...")
+		} else {
+			// 一定間隔でマッチング待機状況をログ出力
+			if a.logThrottle.Allow("waiting_match", waitingLogInterval) {
+				bounds := frame.Client
+				a.detectorLog.Info("マッチング画面を待機中...", "target", captureTarget(frame), "width", bounds.Dx(), "height", bounds.Dy())
+			}
+		}
+		return true
+	}
+
+	// 承認ボタンを監視中
+	// まずマッチング画面がまだ存在するかチェック
+	if !a.detectMatching(frame) {
+		// マッチング画面が検出されなくなった場合、監視を停止
+		a.detectorLog.Info("マッチング画面が検出されなくなりました - 監視を自動停止します")
+		a.readyChecks.ended()
+		a.stopMonitoring(gen)
+		return false
+	}
+	
+	// デバッグモードでは候補の一覧も記録する
+	var buttonPos *detector.Point
+	var trace *detector.Trace
+	var cached bool
+	detectStart := a.clock.Now()
+	if a.snapshots != nil {
+		buttonPos, trace = a.detector.TraceAcceptButton(frame)
+	} else {
+		buttonPos, cached = a.detector.DetectAcceptButton(frame)
+	}
+	method := ""
+	if buttonPos != nil {
+		method = buttonPos.Method
+	}
+	a.metrics.observeDetection(websocket.TargetAccept, method, cached, a.clock.Since(detectStart))
+	if buttonPos == nil {
+		// 一定間隔で承認ボタン検索状況をログ出力
+		if a.logThrottle.Allow("searching_accept", searchingLogInterval) {
+			elapsed := a.clock.Since(start)
+			// 検索エリアの情報も出力
+			roi := img.Bounds()
+			a.detectorLog.Info("承認ボタンを検索中...", "elapsed", elapsed, "target", captureTarget(frame),
+				"client", fmt.Sprintf("%dx%d", frame.Client.Dx(), frame.Client.Dy()), "roi", fmt.Sprintf("%dx%d", roi.Dx(), roi.Dy()))
+		}
+		return true
+	}
+	
+	elapsed := a.clock.Since(start)
+	
+	// 詳細検証スコアを取得
+	verifyScore := a.detector.VerifyAcceptButton(img, buttonPos, 1.0)
+	screenPos := frame.ToScreen(image.Pt(buttonPos.X, buttonPos.Y))
+	a.wsManager.SendDetection(websocket.TargetAccept, screenPos.X, screenPos.Y, verifyScore, elapsed.Milliseconds())
+	a.readyChecks.detected(buttonPos.Method, verifyScore, a.IsDryRun())
+	a.detectorLog.Info("承認ボタンを検出しました", "x", screenPos.X, "y", screenPos.Y,
+		"score", fmt.Sprintf("%.3f", verifyScore), "elapsed", elapsed)
+	
+	// より低い閾値でも許可（検証スコアが低くてもクリック）
+	if verifyScore <= 0.2 {
+		a.detectorLog.Warn("検証スコアが低いため、クリックをスキップしました", "score", fmt.Sprintf("%.3f", verifyScore))
+		a.metrics.skippedLowScore.Inc()
+		a.saveSnapshot(frame, trace, snapshot.DecisionSkippedLowScore, verifyScore)
+		return true
+	}
+	
+	target, ok := a.systemCtrl.ClickAcceptButtonInBounds(a.detector.AcceptButtonBounds(buttonPos).Add(frame.Origin))
+	if !ok {
+		a.systemLog.Error("承認ボタンのクリックに失敗しました", "x", screenPos.X, "y", screenPos.Y)
+		a.metrics.clickFailures.Inc()
+		a.saveSnapshot(frame, trace, snapshot.DecisionClickFailed, verifyScore)
+		return true
+	}
+	// ドライランのクリックは履歴の集計と同じく承認として数えない
+	if !a.IsDryRun() {
+		a.metrics.accepts.Inc()
+	}
+	a.saveSnapshot(frame, trace, snapshot.DecisionClicked, verifyScore)
+	a.readyChecks.clicked()
+	
+	a.wsManager.SendClick(target.X, target.Y, a.IsDryRun())
+	if a.IsDryRun() {
+		a.systemLog.Info("[ドライラン] 承認ボタンのクリックを記録しました", "x", target.X, "y", target.Y)
+	} else {
+		a.systemLog.Info("承認ボタンをクリックしました", "x", target.X, "y", target.Y)
+	}
+	a.log.Info("待機後、マッチング画面の状態をチェックします", "delay", postClickDelay)
+	a.clock.Sleep(postClickDelay)
+	// 待機中に停止（または停止後に再開）された場合は、このループを終了する
+	if !a.isMonitor(gen) {
+		return false
+	}
+	// 5秒後にマッチング画面が検出されるかチェック
+	frame2, err := a.source.Capture(capture.Full)
+	if err == nil {
+		defer frame2.Release()
+		a.latestFrame.Set(frame2)
+	}
+	if err == nil && !a.detectMatching(frame2) {
+		a.detectorLog.Info("マッチング画面が検出されなくなりました - 監視を自動停止します")
+		a.readyChecks.ended()
+		a.stopMonitoring(gen)
+		return false
+	}
+	a.detectorLog.Info("マッチング画面が継続中 - 監視を継続します")
+	return true
 }
 
 func (a *App) StopMonitoring() {
-	if !a.IsRunning() {
-		return
+	a.stopMonitoring(0)
+}
+
+// 監視を終了状態にする（gen が 0 以外で現在の監視と異なる場合は何もしない）
+func (a *App) endMonitoring(gen uint64) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.running || (gen != 0 && gen != a.monitorGen) {
+		return false
 	}
+	a.running = false
+	a.waitingForMatch = false
+	return true
+}
 
-	a.SetRunning(false)
-	a.SetWaitingForMatch(false)
-	a.wsManager.UpdateStatus("停止中")
-	a.wsManager.SendLog("監視を停止しました")
+// 監視ループ自身が停止する場合は自分の世代を渡し、新しい監視を止めないようにする
+func (a *App) stopMonitoring(gen uint64) {
+	if !a.endMonitoring(gen) {
+		return
+	}
+	a.readyChecks.cancel()
+	a.scheduler.SetMode(pollModeLobby)
+	a.publishState(statusStopped)
+	a.log.Info("監視を停止しました")
 }
 
 // 自動監視機能：matching.pngを検出したら自動で監視開始
-func (a *App) StartAutoWatcher() {
+func (a *App) StartAutoWatcher() error {
+	if a.IsAutoWatching() {
+		return nil
+	}
+
 	// テンプレート画像の読み込み
-	if err := a.detector.LoadTemplates(); err != nil {
-		return
+	if err := a.loadTemplates(); err != nil {
+		a.log.Error("テンプレート読み込みエラー", "pack", a.ActiveTemplatePack(), "error", err)
+		return err
 	}
 
-	a.SetAutoWatching(true)
+	a.markLoopStarted()
+	gen := a.beginAutoWatch()
+	a.wsManager.UpdateState(a.CurrentState())
 
 	go func() {
-		ticker := time.NewTicker(1 * time.Second)
-		defer ticker.Stop()
-
-		for a.IsAutoWatching() {
-			<-ticker.C
+		for a.isAutoWatcher(gen) {
+			// 監視状態に応じた間隔で待機
+			a.clock.Sleep(a.scheduler.Interval())
 			// 既に監視中の場合はスキップ
 			if a.IsRunning() {
 				continue
 			}
 
 			// スクリーンショット取得
-			img, err := a.detector.CaptureScreen()
+			frame, err := a.source.Capture(a.planner.Next(a.detector.ActiveRegions(true)...))
+			a.observeCapture(frame, err)
 			if err != nil {
 				continue
 			}
+			a.latestFrame.Set(frame)
 
 			// マッチング画面を検出
-			if a.detector.FastDetectMatchingScreen(img) {
-				a.wsManager.SendLog("マッチング画面を検出 - 自動監視を開始します")
+			detected := a.detectMatching(frame)
+			frame.Release()
+			a.reportStats()
+			if detected {
+				a.detectorLog.Info("マッチング画面を検出 - 自動監視を開始します")
 				a.StartMonitoring()
 			}
 		}
 	}()
+	return nil
 }
 
-func (a *App) TestEnvironment() {
-	start := time.Now()
-	
-	// 画面サイズを取得するため最初にスクリーンショットを撮る
-	img, err := a.detector.CaptureScreen()
-	if err == nil {
-		bounds := img.Bounds()
-		a.wsManager.SendLog(fmt.Sprintf("画面サイズ: %dx%d", bounds.Dx(), bounds.Dy()))
-	}
-	a.wsManager.SendLog(fmt.Sprintf("OS: %s", a.systemCtrl.GetOSName()))
-	
-	if err := a.detector.LoadTemplates(); err != nil {
-		a.wsManager.SendLog(fmt.Sprintf("テンプレート読み込みエラー: %v", err))
-	} else {
-		a.wsManager.SendLog("テンプレート読み込み成功")
-		// テンプレートサイズ情報
-		if acceptTemplate := a.detector.GetAcceptTemplate(); acceptTemplate != nil {
-			acceptBounds := acceptTemplate.Bounds()
-			a.wsManager.SendLog(fmt.Sprintf("承認ボタンテンプレートサイズ: %dx%d", acceptBounds.Dx(), acceptBounds.Dy()))
-		}
-		if matchingTemplate := a.detector.GetMatchingTemplate(); matchingTemplate != nil {
-			matchingBounds := matchingTemplate.Bounds()
-			a.wsManager.SendLog(fmt.Sprintf("マッチングテンプレートサイズ: %dx%d", matchingBounds.Dx(), matchingBounds.Dy()))
-		}
-	}
-	
-	// 検出速度テスト
-	if err == nil {
-		testStart := time.Now()
-		matchingDetected := a.detector.FastDetectMatchingScreen(img)
-		elapsed := time.Since(testStart)
-		a.wsManager.SendLog(fmt.Sprintf("マッチング画面検出テスト: %v (結果: %v)", elapsed, matchingDetected))
-		
-		// 複数手法での承認ボタン検出テスト
-		testStart = time.Now()
-		buttonPos := a.detector.FastDetectAcceptButton(img)
-		elapsed = time.Since(testStart)
-		buttonDetected := buttonPos != nil
-		
-		if buttonDetected {
-			verifyScore := a.detector.VerifyAcceptButton(img, buttonPos, 1.0)
-			a.wsManager.SendLog(fmt.Sprintf("承認ボタン検出テスト: %v (結果: %v, 検証スコア: %.3f, 位置: %d,%d)", 
-				elapsed, buttonDetected, verifyScore, buttonPos.X, buttonPos.Y))
-		} else {
-			a.wsManager.SendLog(fmt.Sprintf("承認ボタン検出テスト: %v (結果: %v)", elapsed, buttonDetected))
-		}
+// 自動監視を停止する（実行中の監視は止めない）
+func (a *App) StopAutoWatcher() {
+	if !a.IsAutoWatching() {
+		return
 	}
-	
-	if !a.systemCtrl.IsSystemSupported() {
-		a.wsManager.SendLog("システム制御が利用できません")
-	} else {
-		a.wsManager.SendLog("システム制御が利用可能です")
+	a.SetAutoWatching(false)
+	a.wsManager.UpdateState(a.CurrentState())
+	a.log.Info("自動監視を停止しました")
+}
+
+// キャプチャ対象の表示名
+func captureTarget(frame *capture.Frame) string {
+	if frame.FromWindow {
+		return "クライアントウィンドウ"
 	}
-	
-	totalElapsed := time.Since(start)
-	a.wsManager.SendLog(fmt.Sprintf("環境テスト完了: %v", totalElapsed))
-}
\ No newline at end of file
+	return "画面全体"
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/detector"
+	"lol-auto-accept/internal/templates"
+)
+
+// キャリブレーションでの検出テストの結果
+type CalibrationResult struct {
+	Pack      templates.Pack  `json:"pack"`
+	Matching  bool            `json:"matching"`   // マッチング画面を検出できたか
+	Accept    *detector.Point `json:"accept"`     // 検出した承認ボタンの位置（検出できなければ null）
+	Score     float64         `json:"score"`      // 承認ボタンの検証スコア
+	Hit       bool            `json:"hit"`        // 検出位置が指定した範囲内か
+	ElapsedMs int64           `json:"elapsed_ms"` // 検出にかかった時間
+}
+
+// キャリブレーション用にクライアント全体をキャプチャして保持する
+func (a *App) CaptureCalibrationFrame() (*capture.Frame, error) {
+	frame, err := a.source.Capture(capture.Full)
+	if err != nil {
+		return nil, err
+	}
+	a.calibrationFrame.Set(frame)
+	return frame, nil
+}
+
+// キャリブレーション用のフレーム（利用後に Release が必要、未取得の場合は nil）
+func (a *App) AcquireCalibrationFrame() *capture.Frame {
+	return a.calibrationFrame.Acquire()
+}
+
+func (a *App) GetTemplateStore() *templates.Store {
+	return a.templates
+}
+
+// キャプチャ済みフレームから指定範囲を切り出してテンプレートパックとして保存し、
+// 同じフレームで検出をテストする（範囲はクライアント座標）
+func (a *App) SaveCalibration(name string, acceptBox, matchingBox image.Rectangle) (*CalibrationResult, error) {
+	if !templates.ValidName(name) {
+		return nil, templates.ErrInvalidName
+	}
+	frame := a.AcquireCalibrationFrame()
+	if frame == nil {
+		return nil, fmt.Errorf("キャリブレーション用の画面がキャプチャされていません")
+	}
+	defer frame.Release()
+
+	accept, err := cropTemplate(frame, acceptBox)
+	if err != nil {
+		return nil, fmt.Errorf("承認ボタンの範囲: %v", err)
+	}
+	matching, err := cropTemplate(frame, matchingBox)
+	if err != nil {
+		return nil, fmt.Errorf("マッチング表示の範囲: %v", err)
+	}
+
+	pack := templates.Pack{
+		Name:    name,
+		Width:   frame.Client.Dx(),
+		Height:  frame.Client.Dy(),
+		Created: a.clock.Now(),
+	}
+	if err := a.SaveTemplatePack(pack, accept, matching); err != nil {
+		return nil, err
+	}
+	a.log.Info("テンプレートパックを保存しました", "pack", name, "width", pack.Width, "height", pack.Height)
+
+	// 監視中の検出器には影響しないよう、新しい検出器でテストする
+	d := detector.NewImageDetector()
+	d.SetTemplates(accept, matching)
+
+	start := a.clock.Now()
+	result := &CalibrationResult{Pack: pack}
+	result.Matching = d.FastDetectMatchingScreen(frame)
+	if pos := d.FastDetectAcceptButton(frame); pos != nil {
+		result.Accept = pos
+		result.Score = d.VerifyAcceptButton(frame.Image, pos, 1.0)
+		result.Hit = image.Pt(pos.X, pos.Y).In(acceptBox)
+	}
+	result.ElapsedMs = a.clock.Since(start).Milliseconds()
+
+	a.detectorLog.Info("キャリブレーションの検出テスト", "pack", name, "matching", result.Matching,
+		"accept", result.Accept != nil, "hit", result.Hit, "score", fmt.Sprintf("%.3f", result.Score))
+	return result, nil
+}
+
+// フレームから範囲を切り出して独立した画像にする
+func cropTemplate(frame *capture.Frame, rect image.Rectangle) (*image.RGBA, error) {
+	rect = rect.Canon()
+	if !rect.In(frame.Image.Bounds()) {
+		return nil, fmt.Errorf("画面の外を含んでいます")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(img, img.Bounds(), frame.Image, rect.Min, draw.Src)
+	if err := templates.Validate(img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
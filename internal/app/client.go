@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// クライアントのAPIへの接続を待つ時間
+const clientAPITimeout = 500 * time.Millisecond
+
+// OS ごとの lockfile の既定の場所（Linux は Wine などの環境ごとに異なるため既定なし）
+func defaultLockfile() string {
+	switch runtime.GOOS {
+	case "windows":
+		return `C:\Riot Games\League of Legends\lockfile`
+	case "darwin":
+		return "/Applications/League of Legends.app/Contents/LoL/lockfile"
+	}
+	return ""
+}
+
+// lockfile（LeagueClient:pid:port:password:protocol）からAPIのポート番号を読む
+func readLockfilePort(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Split(strings.TrimSpace(string(data)), ":")
+	if len(fields) != 5 {
+		return 0, fmt.Errorf("lockfile の形式が不正です: %s", path)
+	}
+	port, err := strconv.Atoi(fields[2])
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("lockfile のポート番号が不正です: %q", fields[2])
+	}
+	return port, nil
+}
+
+// クライアントのAPIに接続できるか（接続するだけで、リクエストは送らない）
+// 起動していない場合は承認するレディチェックもないため warn とする
+func (a *App) checkClient() HealthCheck {
+	check := HealthCheck{Name: "client"}
+	if a.lockfile == "" {
+		check.Status = CheckSkipped
+		check.Detail = "lockfile の場所が分かりません（client.lockfile を設定してください）"
+		return check
+	}
+
+	port, err := readLockfilePort(a.lockfile)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Status = CheckWarn
+		check.Detail = "クライアントが起動していません"
+		return check
+	}
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, clientAPITimeout)
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("クライアントのAPIに接続できません: %v", err)
+		return check
+	}
+	conn.Close()
+	check.Status = CheckOK
+	check.Detail = address
+	return check
+}
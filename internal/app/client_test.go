@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLockfile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lockfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadLockfilePort(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+		wantErr bool
+	}{
+		{"LeagueClient:1234:54321:secret:https", 54321, false},
+		{"LeagueClient:1234:54321:secret:https\n", 54321, false},
+		{"LeagueClient:1234:54321", 0, true},
+		{"LeagueClient:1234:port:secret:https", 0, true},
+		{"LeagueClient:1234:70000:secret:https", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := readLockfilePort(writeLockfile(t, tt.content))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("readLockfilePort(%q) = %d, %v; want %d (error: %v)", tt.content, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	open := listener.Addr().(*net.TCPAddr).Port
+
+	// 閉じたポート（lockfile が残ったままクライアントが終了した場合）
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		lockfile string
+		want     string
+	}{
+		{"unknown location", "", CheckSkipped},
+		{"not running", filepath.Join(t.TempDir(), "lockfile"), CheckWarn},
+		{"malformed", writeLockfile(t, "garbage"), CheckFail},
+		{"reachable", writeLockfile(t, fmt.Sprintf("LeagueClient:1:%d:secret:https", open)), CheckOK},
+		{"unreachable", writeLockfile(t, fmt.Sprintf("LeagueClient:1:%d:secret:https", closedPort)), CheckFail},
+	}
+	for _, tt := range tests {
+		a := &App{lockfile: tt.lockfile}
+		if c := a.checkClient(); c.Status != tt.want {
+			t.Errorf("%s: checkClient() = %+v; want %s", tt.name, c, tt.want)
+		}
+	}
+}
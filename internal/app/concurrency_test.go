@@ -0,0 +1,161 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/sim"
+)
+
+// 並行して実行する環境テストの回数
+const environmentTests = 2
+
+// 自動監視・監視ループ・環境テストを同時に動かしても、シナリオどおりに承認できること
+// 共有している検出器・フレーム・状態の競合は -race で検出する
+func TestConcurrentWatcherMonitorAndEnvironment(t *testing.T) {
+	scenario := sim.DefaultScenario.Scaled(0.5)
+	e := newSimEnv(t, scenario)
+	if err := e.app.StartAutoWatcher(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 環境テストと状態の参照は時計を使わないため、時計を進めている間に別のゴルーチンで実行する
+	// 環境テストは1回に時間がかかるため回数を制限する
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	var tests atomic.Int32
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < environmentTests; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			report := e.app.TestEnvironment()
+			if report.Matching == nil || report.Accept == nil {
+				t.Errorf("environment report without detection tests: %+v", report)
+			}
+			tests.Add(1)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			e.app.CurrentState()
+			e.app.Health()
+			e.app.GetPollRate()
+			if frame := e.app.AcquireLatestFrame(); frame != nil {
+				frame.Release()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	e.run(5*time.Minute, func() bool {
+		return e.client.Phase() == sim.PhaseChampSelect && !e.app.IsRunning()
+	})
+	close(stop)
+	wg.Wait()
+
+	if phase := e.client.Phase(); phase != sim.PhaseChampSelect {
+		t.Fatalf("phase = %s; want %s", phase, sim.PhaseChampSelect)
+	}
+	if e.app.IsRunning() {
+		t.Error("monitoring did not stop")
+	}
+	hits := 0
+	for _, c := range e.client.Clicks() {
+		if !c.Hit {
+			t.Errorf("click outside the accept button: %+v", c)
+			continue
+		}
+		hits++
+	}
+	if want := scenario.Declines + 1; hits != want {
+		t.Errorf("hits = %d; want %d", hits, want)
+	}
+	if tests.Load() == 0 {
+		t.Error("no environment test completed")
+	}
+}
+
+// 自動監視の開始と停止を繰り返しても、古い自動監視のゴルーチンが残らないこと
+func TestAutoWatcherRestart(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+
+	for i := 0; i < 5; i++ {
+		if err := e.app.StartAutoWatcher(); err != nil {
+			t.Fatal(err)
+		}
+		e.app.StopAutoWatcher()
+	}
+	if err := e.app.StartAutoWatcher(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止した自動監視は Sleep から戻ると終了するため、時計を進めると1つだけが残る
+	for i := 0; i < 3; i++ {
+		e.clock.BlockUntil(1)
+		time.Sleep(10 * time.Millisecond)
+		e.clock.AdvanceToNext()
+	}
+	e.clock.BlockUntil(1)
+	time.Sleep(10 * time.Millisecond)
+	if n := e.clock.Sleepers(); n != 1 {
+		t.Errorf("sleeping watchers = %d; want 1", n)
+	}
+}
+
+// 監視を同時に開始したり、待機中に停止・再開したりしても、監視ループは1つだけ動くこと
+func TestMonitoringRestart(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := e.app.StartMonitoring(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	e.clock.BlockUntil(1)
+	time.Sleep(10 * time.Millisecond)
+	if n := e.clock.Sleepers(); n != 1 {
+		t.Errorf("monitor loops after concurrent starts = %d; want 1", n)
+	}
+
+	// 古いループが Sleep している間に停止と開始を繰り返す
+	for i := 0; i < 3; i++ {
+		e.app.StopMonitoring()
+		if err := e.app.StartMonitoring(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 古いループは Sleep から戻ると終了するため、時計を進めると1つだけが残る
+	for i := 0; i < 3; i++ {
+		e.clock.BlockUntil(1)
+		time.Sleep(10 * time.Millisecond)
+		e.clock.AdvanceToNext()
+	}
+	e.clock.BlockUntil(1)
+	time.Sleep(10 * time.Millisecond)
+	if n := e.clock.Sleepers(); n != 1 {
+		t.Errorf("monitor loops after restarting = %d; want 1", n)
+	}
+	if !e.app.IsRunning() {
+		t.Error("monitoring stopped after restarting")
+	}
+}
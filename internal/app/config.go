@@ -0,0 +1,156 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/logging"
+	"lol-auto-accept/internal/system"
+	"lol-auto-accept/internal/templates"
+)
+
+var (
+	ErrNoConfigPath  = errors.New("設定ファイルを使っていないため設定を変更できません")
+	ErrInvalidConfig = errors.New("設定が不正です")
+)
+
+// API から変更できない項目（変更できると操作用サーバーを乗っ取られるため、config.json を直接編集する）
+var lockedServerFields = []string{"token", "address"}
+
+// 使用できるマウス移動のイージング
+var mouseEasings = []string{"linear", "ease-in", "ease-out", "ease-in-out"}
+
+// 設定ファイルの内容（起動時のフラグによる上書きは含まない）
+func (a *App) Config() (*config.Config, error) {
+	path := a.getConfigPath()
+	if path == "" {
+		return nil, ErrNoConfigPath
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return redactConfig(cfg), nil
+}
+
+// 設定ファイルの一部を JSON で変更して保存する
+// マウス移動とテンプレートパックはすぐに反映し、それ以外を変更した場合は restart を true で返す
+func (a *App) UpdateConfig(patch []byte) (*config.Config, bool, error) {
+	path := a.getConfigPath()
+	if path == "" {
+		return nil, false, ErrNoConfigPath
+	}
+	before, err := config.Load(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := checkLockedFields(patch); err != nil {
+		return nil, false, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, false, err
+	}
+
+	if cfg.Templates.Active == "" {
+		cfg.Templates.Active = templates.DefaultPack
+	}
+	if cfg.Templates.Active != a.ActiveTemplatePack() {
+		if err := a.ActivateTemplatePack(cfg.Templates.Active); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := cfg.Save(path); err != nil {
+		return nil, false, err
+	}
+	a.systemCtrl.SetMotion(system.MotionConfig{
+		Humanize: cfg.Mouse.Humanize,
+		Duration: time.Duration(cfg.Mouse.DurationMs) * time.Millisecond,
+		Easing:   cfg.Mouse.Easing,
+		Jitter:   cfg.Mouse.Jitter,
+	})
+
+	// すぐに反映した項目を除いて比較する
+	rest := *cfg
+	rest.Mouse = before.Mouse
+	rest.Templates.Active = before.Templates.Active
+	restart := rest != *before
+	a.log.Info("設定を変更しました", "restart_required", restart)
+	return redactConfig(cfg), restart, nil
+}
+
+// 返す設定からトークンを除く
+func redactConfig(cfg *config.Config) *config.Config {
+	cfg.Server.Token = ""
+	return cfg
+}
+
+// 変更内容に API から変更できない項目が含まれていればエラー（解析できない場合は後の解析でエラーにする）
+func checkLockedFields(patch []byte) error {
+	var fields struct {
+		Server map[string]json.RawMessage `json:"server"`
+	}
+	if json.Unmarshal(patch, &fields) != nil {
+		return nil
+	}
+	for _, name := range lockedServerFields {
+		if _, ok := fields.Server[name]; ok {
+			return fmt.Errorf("%w: server.%s は API から変更できません（config.json を編集してください）", ErrInvalidConfig, name)
+		}
+	}
+	return nil
+}
+
+func (a *App) getConfigPath() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.configPath
+}
+
+func validateConfig(cfg *config.Config) error {
+	if _, err := logging.ParseLevel(cfg.Log.Level); err != nil {
+		return fmt.Errorf("%w: log.level: %v", ErrInvalidConfig, err)
+	}
+	if cfg.Log.MaxSizeMB < 0 || cfg.Log.MaxFiles < 0 {
+		return fmt.Errorf("%w: log.max_size_mb と log.max_files は0以上で指定してください", ErrInvalidConfig)
+	}
+	if cfg.Mouse.DurationMs < 0 {
+		return fmt.Errorf("%w: mouse.duration_ms は0以上で指定してください", ErrInvalidConfig)
+	}
+	if cfg.Mouse.Jitter < 0 || cfg.Mouse.Jitter > 1 {
+		return fmt.Errorf("%w: mouse.jitter は0〜1で指定してください", ErrInvalidConfig)
+	}
+	valid := false
+	for _, easing := range mouseEasings {
+		if cfg.Mouse.Easing == easing {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%w: mouse.easing は linear / ease-in / ease-out / ease-in-out のいずれかで指定してください", ErrInvalidConfig)
+	}
+	if _, _, err := net.SplitHostPort(cfg.Server.Address); err != nil {
+		return fmt.Errorf("%w: server.address: %v", ErrInvalidConfig, err)
+	}
+	if cfg.Server.TLS && (cfg.Server.CertFile == "" || cfg.Server.KeyFile == "") {
+		return fmt.Errorf("%w: server.tls を有効にする場合は server.cert_file と server.key_file を指定してください", ErrInvalidConfig)
+	}
+	if cfg.Debug.MaxSnapshots < 0 {
+		return fmt.Errorf("%w: debug.max_snapshots は0以上で指定してください", ErrInvalidConfig)
+	}
+	return nil
+}
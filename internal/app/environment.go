@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"image"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/detector"
+)
+
+// 環境テストの結果
+type EnvironmentReport struct {
+	Status    string         `json:"status"` // checks の中で最も悪い状態
+	OS        string         `json:"os"`
+	DryRun    bool           `json:"dry_run"`
+	Checks    []HealthCheck  `json:"checks"`
+	Matching  *DetectionTest `json:"matching,omitempty"` // キャプチャに失敗した場合は nil
+	Accept    *DetectionTest `json:"accept,omitempty"`
+	Stats     detector.Stats `json:"stats"`
+	ElapsedMs int64          `json:"elapsed_ms"`
+}
+
+// 検出速度テストの結果（座標はスクリーン座標）
+type DetectionTest struct {
+	Detected  bool    `json:"detected"`
+	ElapsedMs int64   `json:"elapsed_ms"`
+	Score     float64 `json:"score,omitempty"`
+	X         int     `json:"x,omitempty"`
+	Y         int     `json:"y,omitempty"`
+}
+
+// キャプチャ・テンプレート・検出速度・マウス操作を確認し、結果をログにも出力する
+func (a *App) TestEnvironment() EnvironmentReport {
+	start := a.clock.Now()
+	report := EnvironmentReport{OS: a.systemCtrl.GetOSName(), DryRun: a.IsDryRun()}
+
+	// 画面サイズを取得するため最初にスクリーンショットを撮る
+	captureCheck := HealthCheck{Name: "capture"}
+	frame, err := a.source.Capture(capture.Full)
+	if err == nil {
+		defer frame.Release()
+		bounds := frame.Client
+		a.log.Info("キャプチャ対象", "target", captureTarget(frame), "width", bounds.Dx(), "height", bounds.Dy(),
+			"x", frame.Origin.X, "y", frame.Origin.Y)
+		captureCheck.Status = CheckOK
+		captureCheck.Detail = fmt.Sprintf("%s %dx%d (%d, %d)", captureTarget(frame), bounds.Dx(), bounds.Dy(), frame.Origin.X, frame.Origin.Y)
+	} else {
+		a.log.Error("スクリーンショット取得エラー", "error", err)
+		captureCheck.Status = CheckFail
+		captureCheck.Detail = err.Error()
+	}
+	a.systemLog.Info("OS", "os", report.OS)
+
+	templatesCheck := HealthCheck{Name: "templates"}
+	if err := a.loadTemplates(); err != nil {
+		a.log.Error("テンプレート読み込みエラー", "pack", a.ActiveTemplatePack(), "error", err)
+		templatesCheck.Status = CheckFail
+		templatesCheck.Detail = err.Error()
+	} else {
+		a.log.Info("テンプレート読み込み成功", "pack", a.ActiveTemplatePack())
+		templatesCheck.Status = CheckOK
+		templatesCheck.Detail = a.ActiveTemplatePack()
+		// テンプレートサイズ情報
+		if acceptTemplate := a.detector.GetAcceptTemplate(); acceptTemplate != nil {
+			acceptBounds := acceptTemplate.Bounds()
+			a.detectorLog.Info("承認ボタンテンプレートサイズ", "width", acceptBounds.Dx(), "height", acceptBounds.Dy())
+			templatesCheck.Detail += fmt.Sprintf(" accept=%dx%d", acceptBounds.Dx(), acceptBounds.Dy())
+		}
+		if matchingTemplate := a.detector.GetMatchingTemplate(); matchingTemplate != nil {
+			matchingBounds := matchingTemplate.Bounds()
+			a.detectorLog.Info("マッチングテンプレートサイズ", "width", matchingBounds.Dx(), "height", matchingBounds.Dy())
+			templatesCheck.Detail += fmt.Sprintf(" matching=%dx%d", matchingBounds.Dx(), matchingBounds.Dy())
+		}
+	}
+
+	// 検出速度テスト（監視中の結果キャッシュとヒントを使わないよう複製した検出器で測る）
+	if err == nil {
+		d := a.detector.Clone()
+		testStart := a.clock.Now()
+		matchingDetected := d.FastDetectMatchingScreen(frame)
+		elapsed := a.clock.Since(testStart)
+		a.detectorLog.Info("マッチング画面検出テスト", "elapsed", elapsed, "detected", matchingDetected)
+		report.Matching = &DetectionTest{Detected: matchingDetected, ElapsedMs: elapsed.Milliseconds()}
+
+		// 複数手法での承認ボタン検出テスト
+		testStart = a.clock.Now()
+		buttonPos := d.FastDetectAcceptButton(frame)
+		elapsed = a.clock.Since(testStart)
+		buttonDetected := buttonPos != nil
+		report.Accept = &DetectionTest{Detected: buttonDetected, ElapsedMs: elapsed.Milliseconds()}
+
+		if buttonDetected {
+			verifyScore := d.VerifyAcceptButton(frame.Image, buttonPos, 1.0)
+			screenPos := frame.ToScreen(image.Pt(buttonPos.X, buttonPos.Y))
+			a.detectorLog.Info("承認ボタン検出テスト", "elapsed", elapsed, "detected", buttonDetected,
+				"score", fmt.Sprintf("%.3f", verifyScore), "x", screenPos.X, "y", screenPos.Y)
+			report.Accept.Score = verifyScore
+			report.Accept.X, report.Accept.Y = screenPos.X, screenPos.Y
+		} else {
+			a.detectorLog.Info("承認ボタン検出テスト", "elapsed", elapsed, "detected", buttonDetected)
+		}
+	}
+
+	inputCheck := a.checkInput()
+	if a.IsDryRun() {
+		a.systemLog.Info("ドライランモード: マウス操作は行いません")
+	} else if inputCheck.Status == CheckFail {
+		a.systemLog.Warn("システム制御が利用できません")
+	} else {
+		a.systemLog.Info("システム制御が利用可能です")
+	}
+
+	report.Stats = a.detector.Stats()
+	a.detectorLog.Info("フレーム統計", "processed", report.Stats.FramesProcessed, "skipped", report.Stats.FramesSkipped)
+
+	report.Checks = []HealthCheck{captureCheck, templatesCheck, inputCheck, a.checkClient(), a.checkWindow()}
+	report.Status = overallStatus(report.Checks)
+	totalElapsed := a.clock.Since(start)
+	report.ElapsedMs = totalElapsed.Milliseconds()
+	a.log.Info("環境テスト完了", "elapsed", totalElapsed)
+	return report
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"lol-auto-accept/internal/capture"
+)
+
+// ヘルスチェックの各項目と全体の状態
+const (
+	CheckOK      = "ok"
+	CheckWarn    = "warn" // 動作はするが確認が必要
+	CheckFail    = "fail"
+	CheckSkipped = "skipped"
+)
+
+// 監視ループがこの時間（＋ポーリング間隔）以上止まっていたら異常とみなす
+const tickStaleAfter = 30 * time.Second
+
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type HealthReport struct {
+	Status       string        `json:"status"`
+	Running      bool          `json:"running"`
+	AutoWatching bool          `json:"auto_watching"`
+	LastTick     *time.Time    `json:"last_tick,omitempty"`
+	Checks       []HealthCheck `json:"checks"`
+}
+
+// ウィンドウ検出に対応したキャプチャ元
+type windowLocator interface {
+	WindowRect() (image.Rectangle, bool)
+}
+
+// キャプチャの成否と時刻を記録（成功したキャプチャを監視ループの1回分とみなす）
+func (a *App) recordCapture(err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := a.clock.Now()
+	a.lastCapture = now
+	a.lastCaptureErr = err
+	if err == nil {
+		a.lastTick = now
+	}
+}
+
+// 監視ループ（監視中または自動監視中）の開始時刻を記録
+func (a *App) markLoopStarted() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.running && !a.autoWatching {
+		a.loopStarted = a.clock.Now()
+	}
+}
+
+// 生存確認：監視ループが止まっていないか
+func (a *App) Health() HealthReport {
+	report := a.newHealthReport()
+	report.Checks = []HealthCheck{a.checkTick()}
+	report.Status = overallStatus(report.Checks)
+	return report
+}
+
+// 準備状況の確認：キャプチャ・テンプレート・マウス操作・クライアントのAPI・クライアントウィンドウ
+func (a *App) Ready() HealthReport {
+	report := a.newHealthReport()
+	report.Checks = []HealthCheck{
+		a.checkCapture(),
+		a.checkTemplates(),
+		a.checkInput(),
+		a.checkClient(),
+		a.checkWindow(),
+		a.checkTick(),
+	}
+	report.Status = overallStatus(report.Checks)
+	return report
+}
+
+func (a *App) newHealthReport() HealthReport {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	report := HealthReport{Running: a.running, AutoWatching: a.autoWatching}
+	if !a.lastTick.IsZero() {
+		lastTick := a.lastTick
+		report.LastTick = &lastTick
+	}
+	return report
+}
+
+// 1つでも fail があれば fail、warn があれば warn
+func overallStatus(checks []HealthCheck) string {
+	status := CheckOK
+	for _, c := range checks {
+		switch c.Status {
+		case CheckFail:
+			return CheckFail
+		case CheckWarn:
+			status = CheckWarn
+		}
+	}
+	return status
+}
+
+func (a *App) checkTick() HealthCheck {
+	check := HealthCheck{Name: "tick"}
+
+	a.mutex.RLock()
+	active := a.running || a.autoWatching
+	since := a.loopStarted
+	if a.lastTick.After(since) {
+		since = a.lastTick
+	}
+	a.mutex.RUnlock()
+
+	if !active {
+		check.Status = CheckSkipped
+		check.Detail = "監視していません"
+		return check
+	}
+	limit := tickStaleAfter + a.scheduler.Interval()
+	if elapsed := a.clock.Since(since); elapsed > limit {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("監視ループが %s 以上止まっています", elapsed.Round(time.Second))
+		return check
+	}
+	check.Status = CheckOK
+	return check
+}
+
+// 監視ループが記録した直近のキャプチャ結果を返す（ここではキャプチャしない）
+func (a *App) checkCapture() HealthCheck {
+	check := HealthCheck{Name: "capture"}
+
+	a.mutex.RLock()
+	last, err := a.lastCapture, a.lastCaptureErr
+	a.mutex.RUnlock()
+
+	switch {
+	case last.IsZero():
+		check.Status = CheckSkipped
+		check.Detail = "まだキャプチャしていません"
+	case err != nil:
+		check.Status = CheckFail
+		check.Detail = err.Error()
+	default:
+		check.Status = CheckOK
+		check.Detail = fmt.Sprintf("%s 前", a.clock.Since(last).Round(time.Second))
+	}
+	return check
+}
+
+// 最後に読み込んだときの結果を返す（ここでは読み込まない）
+func (a *App) checkTemplates() HealthCheck {
+	check := HealthCheck{Name: "templates", Detail: a.ActiveTemplatePack()}
+
+	a.mutex.RLock()
+	err := a.templatesErr
+	a.mutex.RUnlock()
+
+	switch {
+	case err != nil:
+		check.Status = CheckFail
+		check.Detail = err.Error()
+	case a.detector.GetAcceptTemplate() == nil || a.detector.GetMatchingTemplate() == nil:
+		check.Status = CheckWarn
+		check.Detail += " (未読み込み。監視の開始時に読み込みます)"
+	default:
+		check.Status = CheckOK
+	}
+	return check
+}
+
+func (a *App) checkInput() HealthCheck {
+	check := HealthCheck{Name: "input", Detail: a.systemCtrl.GetOSName()}
+	switch {
+	case a.IsDryRun():
+		check.Status = CheckOK
+		check.Detail = "ドライラン（クリックは記録のみ）"
+	case a.inputSupported:
+		check.Status = CheckOK
+	default:
+		check.Status = CheckFail
+		check.Detail = "システム制御が利用できません"
+	}
+	return check
+}
+
+// キャプチャ対象のウィンドウが見つかるか
+// 見つからない場合も画面全体をキャプチャして動作するため warn とする
+func (a *App) checkWindow() HealthCheck {
+	check := HealthCheck{Name: "window"}
+	locator, ok := a.source.(windowLocator)
+	if !a.windowMode || !ok {
+		check.Status = CheckSkipped
+		check.Detail = "ウィンドウ検出を使っていません"
+		return check
+	}
+	rect, found := locator.WindowRect()
+	if !found {
+		check.Status = CheckWarn
+		check.Detail = capture.ErrWindowNotFound.Error()
+		return check
+	}
+	check.Status = CheckOK
+	check.Detail = fmt.Sprintf("%dx%d (%d, %d)", rect.Dx(), rect.Dy(), rect.Min.X, rect.Min.Y)
+	return check
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/sim"
+)
+
+// 準備状況の確認では名前が name の項目を返す
+func findCheck(t *testing.T, report HealthReport, name string) HealthCheck {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %s not found in %+v", name, report.Checks)
+	return HealthCheck{}
+}
+
+// 準備状況の確認はキャプチャもテンプレートの読み込みも行わず、記録済みの状態を返す
+func TestReadyReportsRecordedState(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+
+	report := e.app.Ready()
+	if c := findCheck(t, report, "capture"); c.Status != CheckSkipped {
+		t.Errorf("capture before the first capture = %+v; want %s", c, CheckSkipped)
+	}
+	if c := findCheck(t, report, "templates"); c.Status != CheckWarn {
+		t.Errorf("templates before loading = %+v; want %s", c, CheckWarn)
+	}
+	if e.app.detector.GetAcceptTemplate() != nil {
+		t.Error("Ready loaded the templates")
+	}
+	e.app.mutex.RLock()
+	captured := !e.app.lastCapture.IsZero()
+	e.app.mutex.RUnlock()
+	if captured {
+		t.Error("Ready captured the screen")
+	}
+
+	e.app.recordCapture(errors.New("capture failed"))
+	if err := e.app.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	report = e.app.Ready()
+	if c := findCheck(t, report, "capture"); c.Status != CheckFail || c.Detail != "capture failed" {
+		t.Errorf("capture after an error = %+v; want %s", c, CheckFail)
+	}
+	if c := findCheck(t, report, "templates"); c.Status != CheckOK {
+		t.Errorf("templates after loading = %+v; want %s", c, CheckOK)
+	}
+
+	e.app.recordCapture(nil)
+	e.clock.Advance(3 * time.Second)
+	if c := findCheck(t, e.app.Ready(), "capture"); c.Status != CheckOK || c.Detail != "3s 前" {
+		t.Errorf("capture after a success = %+v; want %s with the age", c, CheckOK)
+	}
+}
+
+// 読み込みに失敗したテンプレートパックは fail として報告する
+func TestReadyReportsTemplateLoadError(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+	e.app.mutex.Lock()
+	e.app.activePack = "missing"
+	e.app.mutex.Unlock()
+
+	if err := e.app.loadTemplates(); err == nil {
+		t.Fatal("loadTemplates succeeded for a missing pack")
+	}
+	if c := findCheck(t, e.app.Ready(), "templates"); c.Status != CheckFail {
+		t.Errorf("templates after a load error = %+v; want %s", c, CheckFail)
+	}
+
+	if err := e.app.ActivateTemplatePack("sim"); err != nil {
+		t.Fatal(err)
+	}
+	if c := findCheck(t, e.app.Ready(), "templates"); c.Status != CheckOK {
+		t.Errorf("templates after switching packs = %+v; want %s", c, CheckOK)
+	}
+}
+
+// マウス操作の可否は起動時に確認した結果を返す
+func TestReadyInputUsesStartupResult(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+	if c := findCheck(t, e.app.Ready(), "input"); c.Status != CheckOK {
+		t.Errorf("input = %+v; want %s", c, CheckOK)
+	}
+	e.app.inputSupported = false
+	if c := findCheck(t, e.app.Ready(), "input"); c.Status != CheckFail {
+		t.Errorf("input without a backend = %+v; want %s", c, CheckFail)
+	}
+}
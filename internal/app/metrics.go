@@ -0,0 +1,116 @@
+package app
+
+import (
+	"time"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/metrics"
+	"lol-auto-accept/internal/websocket"
+)
+
+const metricsPrefix = "lol_auto_accept_"
+
+// 監視の状態（メトリクスの state ラベル）
+const (
+	stateStopped         = "stopped"
+	stateWaitingMatch    = "waiting_match"
+	stateSearchingAccept = "searching_accept"
+)
+
+// 検出1回あたりの時間のバケット（秒）
+var detectionBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// /metrics で公開する値
+type appMetrics struct {
+	registry        *metrics.Registry
+	framesCaptured  *metrics.Counter
+	captureErrors   *metrics.Counter
+	detection       *metrics.HistogramVec
+	accepts         *metrics.Counter
+	skippedLowScore *metrics.Counter
+	clickFailures   *metrics.Counter
+}
+
+func newAppMetrics(a *App) *appMetrics {
+	r := metrics.NewRegistry()
+	m := &appMetrics{
+		registry:        r,
+		framesCaptured:  r.NewCounter(metricsPrefix+"frames_captured_total", "キャプチャに成功したフレーム数"),
+		captureErrors:   r.NewCounter(metricsPrefix+"capture_errors_total", "キャプチャの失敗回数"),
+		detection:       r.NewHistogramVec(metricsPrefix+"detection_duration_seconds", "検出1回あたりの時間", detectionBuckets, "target", "method"),
+		accepts:         r.NewCounter(metricsPrefix+"accepts_total", "承認ボタンのクリック回数（ドライランは含まない）"),
+		skippedLowScore: r.NewCounter(metricsPrefix+"clicks_skipped_low_score_total", "検証スコアが低いためクリックしなかった回数"),
+		clickFailures:   r.NewCounter(metricsPrefix+"click_failures_total", "クリックの失敗回数"),
+	}
+	r.NewCounterFunc(metricsPrefix+"frames_processed_total", "検出処理を行ったフレーム数", func() float64 {
+		return float64(a.GetStats().FramesProcessed)
+	})
+	r.NewCounterFunc(metricsPrefix+"frames_skipped_total", "前回から変化がなく検出を省略したフレーム数", func() float64 {
+		return float64(a.GetStats().FramesSkipped)
+	})
+	r.NewStateSet(metricsPrefix+"state", "監視の状態", "state",
+		[]string{stateStopped, stateWaitingMatch, stateSearchingAccept}, a.monitorState)
+	r.NewGaugeFunc(metricsPrefix+"auto_watching", "自動監視が有効なら 1", func() float64 {
+		return boolGauge(a.IsAutoWatching())
+	})
+	r.NewGaugeFunc(metricsPrefix+"dry_run", "ドライランモードなら 1", func() float64 {
+		return boolGauge(a.IsDryRun())
+	})
+	r.NewGaugeFunc(metricsPrefix+"poll_interval_seconds", "現在のポーリング間隔", func() float64 {
+		interval, _ := a.GetPollRate()
+		return interval.Seconds()
+	})
+	r.NewGaugeFunc(metricsPrefix+"websocket_clients", "接続中のWebSocketクライアント数", func() float64 {
+		return float64(a.wsManager.ClientCount())
+	})
+	return m
+}
+
+// 検出時間の method ラベル（前回の結果を使った場合は cached、検出しなかった場合は none）
+const (
+	methodCached = "cached"
+	methodNone   = "none"
+)
+
+func (m *appMetrics) observeDetection(target, method string, cached bool, elapsed time.Duration) {
+	switch {
+	case cached:
+		method = methodCached
+	case method == "":
+		method = methodNone
+	}
+	m.detection.With(target, method).Observe(elapsed.Seconds())
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// メトリクスのレジストリ（/metrics 用）
+func (a *App) GetMetrics() *metrics.Registry {
+	return a.metrics.registry
+}
+
+func (a *App) monitorState() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	switch {
+	case !a.running:
+		return stateStopped
+	case a.waitingForMatch:
+		return stateWaitingMatch
+	default:
+		return stateSearchingAccept
+	}
+}
+
+// マッチング画面の検出（時間をメトリクスに記録する）
+func (a *App) detectMatching(frame *capture.Frame) bool {
+	start := a.clock.Now()
+	method, cached := a.detector.DetectMatchingScreen(frame)
+	a.metrics.observeDetection(websocket.TargetMatching, method, cached, a.clock.Since(start))
+	return method != ""
+}
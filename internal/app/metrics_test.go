@@ -0,0 +1,85 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/sim"
+	"lol-auto-accept/internal/system"
+)
+
+// マッチング画面の検出時間は、実際に使った手法・未検出・前回の結果の再利用で分けて記録する
+func TestDetectionMetricsMethod(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+	if err := e.app.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+
+	detect := func() bool {
+		frame, err := e.client.Capture(capture.Full)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer frame.Release()
+		return e.app.detectMatching(frame)
+	}
+
+	if detect() {
+		t.Fatal("matching detected in the lobby")
+	}
+	detect() // 変化がないため前回の結果を使う
+	e.clock.Advance(sim.DefaultScenario.Lobby)
+	if !detect() {
+		t.Fatal("matching not detected in the queue")
+	}
+
+	var out strings.Builder
+	if err := e.app.GetMetrics().Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"none", "cached", "template"} {
+		want := `detection_duration_seconds_count{target="matching",method="` + method + `"} 1`
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics missing %s:\n%s", want, out.String())
+		}
+	}
+}
+
+// ドライランのクリックは履歴の集計と同じく承認の回数に含めない
+func TestAcceptMetricsExcludeDryRun(t *testing.T) {
+	scenario := sim.DefaultScenario
+	scenario.Declines = 0
+	tests := []struct {
+		name       string
+		controller func(client *sim.Client) *system.Controller
+		want       string
+	}{
+		{"click", func(client *sim.Client) *system.Controller { return system.NewFakeController(client) }, "1"},
+		{"dry run", func(*sim.Client) *system.Controller { return system.NewDryRunController() }, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSimEnvWith(t, scenario, tt.controller)
+			if err := e.app.StartMonitoring(); err != nil {
+				t.Fatal(err)
+			}
+			e.run(time.Minute, func() bool {
+				return len(e.client.Clicks()) > 0 || len(e.app.systemCtrl.RecordedClicks()) > 0
+			})
+			if len(e.client.Clicks()) == 0 && len(e.app.systemCtrl.RecordedClicks()) == 0 {
+				t.Fatal("accept button was not clicked")
+			}
+
+			var out strings.Builder
+			if err := e.app.GetMetrics().Write(&out); err != nil {
+				t.Fatal(err)
+			}
+			want := metricsPrefix + "accepts_total " + tt.want + "\n"
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("metrics missing %q:\n%s", want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/history"
+)
+
+const (
+	// クリックからこの時間内に再び承認ボタンを検出した場合は同じレディチェックとみなす
+	// （それより後であれば、他のプレイヤーの辞退でキューに戻り、新しいレディチェックが始まった）
+	sameReadyCheckWindow = 15 * time.Second
+	// 承認後この時間内にキューに戻った場合は、チャンピオン選択で誰かが抜けたとみなす
+	dodgeWindow = 10 * time.Minute
+)
+
+// 監視中のレディチェックを追跡し、結果を履歴に残す
+type readyCheckTracker struct {
+	clock clock.Clock
+	store *history.Store // nil の場合は記録しない
+	log   *slog.Logger
+
+	mutex        sync.Mutex
+	current      *history.Record // 結果が決まっていないレディチェック
+	lastSeen     time.Time       // current の承認ボタンを最後に検出、またはクリックした時刻
+	lastAccepted *history.Record // 直前に承認したレディチェック（抜けの判定用）
+	acceptedAt   time.Time
+}
+
+func newReadyCheckTracker(clk clock.Clock, store *history.Store, log *slog.Logger) *readyCheckTracker {
+	return &readyCheckTracker{clock: clk, store: store, log: log}
+}
+
+// 承認ボタンを検出した
+func (t *readyCheckTracker) detected(method string, score float64, dryRun bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	if t.current != nil && now.Sub(t.lastSeen) > sameReadyCheckWindow {
+		// 前のレディチェックは終わっている
+		if t.current.Clicks > 0 {
+			t.finish(history.OutcomeDeclined)
+		} else {
+			t.finish(history.OutcomeMissed)
+		}
+	}
+	if t.current == nil {
+		t.current = &history.Record{
+			ID:     history.NewID(now),
+			Time:   now,
+			Method: method,
+			Score:  score,
+			DryRun: dryRun,
+		}
+	}
+	t.lastSeen = now
+}
+
+// 承認ボタンをクリックした（承認までの時間はレディチェックを最初に検出してからの時間）
+func (t *readyCheckTracker) clicked() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.current == nil {
+		return
+	}
+	now := t.clock.Now()
+	if t.current.Clicks == 0 {
+		t.current.TimeToAcceptMs = now.Sub(t.current.Time).Milliseconds()
+	}
+	t.current.Clicks++
+	t.lastSeen = now
+}
+
+// マッチング画面が消えた（クリックしてからすぐであればチャンピオン選択に進んだ）
+// クリックしてから sameReadyCheckWindow より後に消えた場合は、クリックがレディチェックにつながらなかった誤検出とみなす
+func (t *readyCheckTracker) ended() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.current == nil {
+		return
+	}
+	switch {
+	case t.current.Clicks == 0:
+		t.finish(history.OutcomeMissed)
+	case t.clock.Since(t.lastSeen) > sameReadyCheckWindow:
+		t.finish(history.OutcomeFalsePositive)
+	default:
+		t.acceptedAt = t.clock.Now()
+		t.lastAccepted = t.finish(history.OutcomeAccepted)
+	}
+}
+
+// 手動で監視を停止した（結果が分からないため記録しない）
+func (t *readyCheckTracker) cancel() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.current = nil
+}
+
+// キュー（マッチング画面）を検出した
+func (t *readyCheckTracker) queueStarted() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.lastAccepted == nil {
+		return
+	}
+	record := *t.lastAccepted
+	t.lastAccepted = nil
+	if t.clock.Since(t.acceptedAt) > dodgeWindow {
+		return
+	}
+	record.Outcome = history.OutcomeDodged
+	t.put(record)
+}
+
+func (t *readyCheckTracker) finish(outcome history.Outcome) *history.Record {
+	record := t.current
+	t.current = nil
+	record.Outcome = outcome
+	t.put(*record)
+	return record
+}
+
+func (t *readyCheckTracker) put(record history.Record) {
+	t.log.Info("レディチェックの結果を記録しました", "outcome", string(record.Outcome), "method", record.Method,
+		"clicks", record.Clicks, "time_to_accept_ms", record.TimeToAcceptMs)
+	if t.store == nil {
+		return
+	}
+	if err := t.store.Put(record); err != nil {
+		t.log.Error("履歴の保存に失敗", "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/history"
+	"lol-auto-accept/internal/logging"
+)
+
+func newTestTracker(t *testing.T) (*readyCheckTracker, *clock.Fake, *history.Store) {
+	t.Helper()
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.NewFake(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	return newReadyCheckTracker(clk, store, logging.For(logging.ComponentApp)), clk, store
+}
+
+func TestReadyCheckTimeToAcceptFromFirstDetection(t *testing.T) {
+	tracker, clk, store := newTestTracker(t)
+
+	tracker.queueStarted()
+	tracker.detected("template", 0.9, false)
+	clk.Advance(300 * time.Millisecond)
+	tracker.detected("template", 0.9, false)
+	clk.Advance(200 * time.Millisecond)
+	tracker.clicked()
+	clk.Advance(time.Second)
+	tracker.clicked()
+	tracker.ended()
+
+	records := store.Records(0)
+	if len(records) != 1 {
+		t.Fatalf("records = %d; want 1", len(records))
+	}
+	r := records[0]
+	if r.Outcome != history.OutcomeAccepted || r.TimeToAcceptMs != 500 || r.Clicks != 2 {
+		t.Errorf("record = %+v; want accepted after 500ms with 2 clicks", r)
+	}
+}
+
+func TestReadyCheckDeclinedAndDodged(t *testing.T) {
+	tracker, clk, store := newTestTracker(t)
+
+	tracker.detected("color", 0.5, false)
+	tracker.clicked()
+	// 他のプレイヤーが辞退して新しいレディチェックが始まった
+	clk.Advance(sameReadyCheckWindow + time.Second)
+	tracker.detected("template", 0.9, false)
+	tracker.clicked()
+	tracker.ended()
+	// チャンピオン選択で解散してキューに戻った
+	clk.Advance(time.Minute)
+	tracker.queueStarted()
+
+	var outcomes []history.Outcome
+	for _, r := range store.Records(0) {
+		outcomes = append(outcomes, r.Outcome)
+	}
+	want := []history.Outcome{history.OutcomeDodged, history.OutcomeDeclined}
+	if len(outcomes) != len(want) || outcomes[0] != want[0] || outcomes[1] != want[1] {
+		t.Errorf("outcomes = %v; want %v", outcomes, want)
+	}
+}
+
+func TestReadyCheckDodgeWindowExpires(t *testing.T) {
+	tracker, clk, store := newTestTracker(t)
+
+	tracker.detected("template", 0.9, false)
+	tracker.clicked()
+	tracker.ended()
+	// 試合が終わってから次のキューに入った場合は抜けではない
+	clk.Advance(dodgeWindow + time.Second)
+	tracker.queueStarted()
+
+	records := store.Records(0)
+	if len(records) != 1 || records[0].Outcome != history.OutcomeAccepted {
+		t.Errorf("records = %+v; want one accepted", records)
+	}
+}
+
+func TestReadyCheckMissedAfterWindow(t *testing.T) {
+	tracker, clk, store := newTestTracker(t)
+
+	// クリックしないまま承認ボタンが消え、しばらく後に次のレディチェックが来た
+	tracker.detected("template", 0.9, false)
+	clk.Advance(sameReadyCheckWindow)
+	tracker.detected("template", 0.9, false)
+	if n := len(store.Records(0)); n != 0 {
+		t.Fatalf("records = %d within the window; want 0", n)
+	}
+	clk.Advance(sameReadyCheckWindow + time.Millisecond)
+	tracker.detected("template", 0.9, false)
+
+	records := store.Records(0)
+	if len(records) != 1 || records[0].Outcome != history.OutcomeMissed {
+		t.Errorf("records = %+v; want one missed", records)
+	}
+}
+
+func TestReadyCheckFalsePositive(t *testing.T) {
+	tracker, clk, store := newTestTracker(t)
+
+	// キュー中に誤検出してクリックしたが、何も起きないままキューをやめた
+	tracker.detected("edge", 0.3, false)
+	tracker.clicked()
+	clk.Advance(sameReadyCheckWindow + time.Second)
+	tracker.ended()
+	// 承認したわけではないため、次のキューは抜けとみなさない
+	clk.Advance(time.Minute)
+	tracker.queueStarted()
+
+	records := store.Records(0)
+	if len(records) != 1 {
+		t.Fatalf("len(records) = %d; want 1", len(records))
+	}
+	if r := records[0]; r.Outcome != history.OutcomeFalsePositive || r.Clicks != 1 {
+		t.Errorf("record = %+v; want %s with 1 click", r, history.OutcomeFalsePositive)
+	}
+}
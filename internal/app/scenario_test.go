@@ -0,0 +1,81 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/detector"
+	"lol-auto-accept/internal/history"
+	"lol-auto-accept/internal/sim"
+)
+
+// 偽のクライアントに対してアプリ全体を動かし、チャンピオン選択まで進んで監視が自動停止すること
+// 時間は偽の時計で進めるため、検出にかかる時間に関係なく同じ結果になる
+func TestClientScenarios(t *testing.T) {
+	tests := []struct {
+		name     string
+		declines int
+	}{
+		{name: "全員が承認", declines: 0},
+		{name: "1回辞退", declines: 1},
+		{name: "2回辞退", declines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 辞退からの次のレディチェックを別のものとして記録するため、表示時間は縮めない
+			scenario := sim.DefaultScenario
+			scenario.Declines = tt.declines
+			e := newSimEnv(t, scenario)
+			if err := e.app.StartAutoWatcher(); err != nil {
+				t.Fatal(err)
+			}
+
+			start := e.clock.Now()
+			e.run(5*time.Minute, func() bool {
+				return e.client.Phase() == sim.PhaseChampSelect && !e.app.IsRunning()
+			})
+			e.app.StopAutoWatcher()
+
+			if phase := e.client.Phase(); phase != sim.PhaseChampSelect {
+				t.Fatalf("phase = %s; want %s", phase, sim.PhaseChampSelect)
+			}
+			if e.app.IsRunning() {
+				t.Error("monitoring did not stop")
+			}
+
+			// レディチェックごとに承認ボタンを1回だけクリックする
+			want := tt.declines + 1
+			clicks := e.client.Clicks()
+			if len(clicks) != want {
+				t.Errorf("len(clicks) = %d; want %d (clicks: %+v)", len(clicks), want, clicks)
+			}
+			for _, c := range clicks {
+				if !c.Hit || !c.Point.In(e.client.AcceptBox()) {
+					t.Errorf("click outside the accept button: %+v", c)
+				}
+			}
+
+			// 履歴は新しい順で、最後だけがチャンピオン選択に進んだ記録
+			records := e.app.GetHistory().Records(0)
+			if len(records) != want {
+				t.Fatalf("len(records) = %d; want %d (records: %+v)", len(records), want, records)
+			}
+			for i, r := range records {
+				outcome := history.OutcomeDeclined
+				if i == 0 {
+					outcome = history.OutcomeAccepted
+				}
+				if r.Outcome != outcome {
+					t.Errorf("records[%d].Outcome = %s; want %s", i, r.Outcome, outcome)
+				}
+				if r.Clicks != 1 || r.DryRun || r.Method != detector.MethodTemplate {
+					t.Errorf("records[%d] = %+v; want one template click", i, r)
+				}
+				if r.Time.Before(start) {
+					t.Errorf("records[%d].Time = %v; want not before %v", i, r.Time, start)
+				}
+			}
+		})
+	}
+}
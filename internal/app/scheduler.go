@@ -0,0 +1,126 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// 監視状態ごとのポーリング間隔
+const (
+	idlePollInterval     = 2 * time.Second        // クライアントウィンドウなし
+	lobbyPollInterval    = 500 * time.Millisecond // ロビー（マッチング画面待ち）
+	matchingPollInterval = 100 * time.Millisecond // マッチング画面表示中
+
+	// 連続してキャプチャに失敗した場合のバックオフ
+	captureErrorThreshold = 3
+	maxBackoffInterval    = 10 * time.Second
+)
+
+type pollMode string
+
+const (
+	pollModeIdle     pollMode = "idle"
+	pollModeLobby    pollMode = "lobby"
+	pollModeMatching pollMode = "matching"
+	pollModeBackoff  pollMode = "backoff"
+)
+
+// 監視状態に応じてポーリング間隔を切り替える
+type pollScheduler struct {
+	mutex         sync.Mutex
+	mode          pollMode
+	captureErrors int
+	interval      time.Duration
+	onChange      func(interval time.Duration, mode pollMode)
+}
+
+func newPollScheduler(onChange func(interval time.Duration, mode pollMode)) *pollScheduler {
+	return &pollScheduler{
+		mode:     pollModeLobby,
+		interval: lobbyPollInterval,
+		onChange: onChange,
+	}
+}
+
+// 次のポーリングまでの待機時間
+func (s *pollScheduler) Interval() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.interval
+}
+
+// 現在のポーリングモード（バックオフ中は pollModeBackoff）
+func (s *pollScheduler) Mode() pollMode {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.effectiveMode()
+}
+
+func (s *pollScheduler) SetMode(mode pollMode) {
+	s.mutex.Lock()
+	s.mode = mode
+	s.mutex.Unlock()
+	s.update()
+}
+
+// キャプチャ成功時に呼び出す（クライアントウィンドウがなければ低頻度に切り替える）
+// マッチング画面の表示中は承認を遅らせないよう低頻度には切り替えない
+func (s *pollScheduler) CaptureSucceeded(clientPresent bool) {
+	s.mutex.Lock()
+	s.captureErrors = 0
+	if !clientPresent && s.mode != pollModeMatching {
+		s.mode = pollModeIdle
+	} else if s.mode == pollModeIdle {
+		s.mode = pollModeLobby
+	}
+	s.mutex.Unlock()
+	s.update()
+}
+
+func (s *pollScheduler) CaptureFailed() {
+	s.mutex.Lock()
+	s.captureErrors++
+	s.mutex.Unlock()
+	s.update()
+}
+
+func (s *pollScheduler) effectiveMode() pollMode {
+	if s.captureErrors >= captureErrorThreshold {
+		return pollModeBackoff
+	}
+	return s.mode
+}
+
+func (s *pollScheduler) baseInterval() time.Duration {
+	switch s.mode {
+	case pollModeIdle:
+		return idlePollInterval
+	case pollModeMatching:
+		return matchingPollInterval
+	default:
+		return lobbyPollInterval
+	}
+}
+
+// 間隔を再計算し、変化があれば通知する
+func (s *pollScheduler) update() {
+	s.mutex.Lock()
+	interval := s.baseInterval()
+	if s.captureErrors >= captureErrorThreshold {
+		// 失敗が続くほど間隔を倍々に延ばす
+		for i := captureErrorThreshold; i <= s.captureErrors && interval < maxBackoffInterval; i++ {
+			interval *= 2
+		}
+		if interval > maxBackoffInterval {
+			interval = maxBackoffInterval
+		}
+	}
+	changed := interval != s.interval
+	s.interval = interval
+	mode := s.effectiveMode()
+	s.mutex.Unlock()
+
+	if changed && s.onChange != nil {
+		s.onChange(interval, mode)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/sim"
+)
+
+func TestSchedulerIdleWhenClientMissing(t *testing.T) {
+	s := newPollScheduler(nil)
+	s.CaptureSucceeded(false)
+	if s.Mode() != pollModeIdle || s.Interval() != idlePollInterval {
+		t.Fatalf("mode = %s, interval = %s; want idle", s.Mode(), s.Interval())
+	}
+	s.CaptureSucceeded(true)
+	if s.Mode() != pollModeLobby || s.Interval() != lobbyPollInterval {
+		t.Fatalf("mode = %s, interval = %s; want lobby", s.Mode(), s.Interval())
+	}
+}
+
+func TestSchedulerKeepsMatchingWhenClientMissing(t *testing.T) {
+	s := newPollScheduler(nil)
+	s.SetMode(pollModeMatching)
+	s.CaptureSucceeded(false)
+	if s.Mode() != pollModeMatching || s.Interval() != matchingPollInterval {
+		t.Fatalf("mode = %s, interval = %s; want matching", s.Mode(), s.Interval())
+	}
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	s := newPollScheduler(nil)
+	for i := 0; i < captureErrorThreshold; i++ {
+		s.CaptureFailed()
+	}
+	if s.Mode() != pollModeBackoff || s.Interval() != 2*lobbyPollInterval {
+		t.Fatalf("mode = %s, interval = %s; want backoff", s.Mode(), s.Interval())
+	}
+	for i := 0; i < 10; i++ {
+		s.CaptureFailed()
+	}
+	if s.Interval() != maxBackoffInterval {
+		t.Fatalf("interval = %s; want %s", s.Interval(), maxBackoffInterval)
+	}
+	s.CaptureSucceeded(true)
+	if s.Mode() != pollModeLobby {
+		t.Fatalf("mode = %s; want lobby after success", s.Mode())
+	}
+}
+
+// 監視ループと自動監視はスケジューラーの間隔で時計を待つ
+func TestPollingFollowsScheduler(t *testing.T) {
+	e := newSimEnv(t, sim.DefaultScenario)
+	if err := e.app.StartAutoWatcher(); err != nil {
+		t.Fatal(err)
+	}
+
+	// ロビーでは自動監視だけがロビーの間隔で待つ
+	for i := 0; i < 3; i++ {
+		e.waitUntilIdle()
+		if step, _ := e.clock.AdvanceToNext(); step != lobbyPollInterval {
+			t.Fatalf("step = %s in lobby; want %s", step, lobbyPollInterval)
+		}
+	}
+
+	// キューに入るとマッチング画面を検出し、監視ループがマッチング中の間隔に切り替える
+	e.run(sim.DefaultScenario.Lobby+5*time.Second, func() bool {
+		_, mode := e.app.GetPollRate()
+		return mode == string(pollModeMatching)
+	})
+	if interval, mode := e.app.GetPollRate(); mode != string(pollModeMatching) || interval != matchingPollInterval {
+		t.Fatalf("poll rate = %s %s after queue; want matching", interval, mode)
+	}
+	e.waitUntilIdle()
+	if step, _ := e.clock.AdvanceToNext(); step > matchingPollInterval {
+		t.Errorf("step = %s while matching; want <= %s", step, matchingPollInterval)
+	}
+}
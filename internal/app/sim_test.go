@@ -0,0 +1,131 @@
+package app
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/sim"
+	"lol-auto-accept/internal/synth"
+	"lol-auto-accept/internal/system"
+	"lol-auto-accept/internal/templates"
+)
+
+// テストで使うクライアントの大きさ（検出に時間がかからないよう小さくする）
+// シミュレーターはテンプレートを幅 1280 基準で縮小して描くため、検出器には縮小後の大きさのテンプレートを渡す
+var (
+	simClientSize   = image.Pt(320, 180)
+	simClientOrigin = image.Pt(100, 50)
+	simUpscale      = 1280 / simClientSize.X
+)
+
+// 2色の縦縞のテンプレート（背景やほかのテンプレートと取り違えない配色にする）
+func stripes(w, h, width int, a, b color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := a
+			if (x/width)%2 == 1 {
+				c = b
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// 検出器用のテンプレート
+func simTemplates() (accept, matching *image.RGBA) {
+	accept = stripes(48, 16, 4, color.RGBA{R: 230, G: 200, B: 40, A: 255}, color.RGBA{R: 20, G: 90, B: 200, A: 255})
+	matching = stripes(64, 16, 4, color.RGBA{R: 200, G: 40, B: 180, A: 255}, color.RGBA{R: 40, G: 190, B: 60, A: 255})
+	return accept, matching
+}
+
+// シミュレーターの描画用に拡大したテンプレート（縮小すると検出器用のテンプレートと同じになる）
+func upscale(img *image.RGBA, factor int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < out.Bounds().Dy(); y++ {
+		for x := 0; x < out.Bounds().Dx(); x++ {
+			out.SetRGBA(x, y, img.RGBAAt(x/factor, y/factor))
+		}
+	}
+	return out
+}
+
+// 偽のクライアント・偽の時計・テスト用テンプレートパックで動くアプリ
+type simEnv struct {
+	app    *App
+	client *sim.Client
+	clock  *clock.Fake
+}
+
+func newSimEnv(t *testing.T, scenario sim.Scenario) *simEnv {
+	t.Helper()
+	return newSimEnvWith(t, scenario, func(client *sim.Client) *system.Controller {
+		return system.NewFakeController(client)
+	})
+}
+
+// マウス操作を指定して作成（ドライランの確認用）
+func newSimEnvWith(t *testing.T, scenario sim.Scenario, controller func(client *sim.Client) *system.Controller) *simEnv {
+	t.Helper()
+	dir := t.TempDir()
+
+	accept, matching := simTemplates()
+	store := templates.NewStore(filepath.Join(dir, "templates"))
+	if err := store.Save(templates.Pack{Name: "sim"}, accept, matching); err != nil {
+		t.Fatal(err)
+	}
+	gen := synth.NewGenerator(upscale(accept, simUpscale), upscale(matching, simUpscale), 1)
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	client, err := sim.NewClient(gen, clk, scenario, simClientSize, simClientOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Templates.Dir = store.Dir()
+	cfg.Templates.Active = "sim"
+	cfg.History.File = filepath.Join(dir, "ready_checks.jsonl")
+	a := NewAppWith(cfg, client, controller(client), clk)
+	t.Cleanup(func() {
+		a.StopAutoWatcher()
+		a.StopMonitoring()
+	})
+	return &simEnv{app: a, client: client, clock: clk}
+}
+
+// 自動監視と（監視中は）監視ループがどちらも Sleep に入るまで待つ
+func (e *simEnv) waitUntilIdle() {
+	for {
+		want := 0
+		if e.app.IsAutoWatching() {
+			want++
+		}
+		if e.app.IsRunning() {
+			want++
+		}
+		if e.clock.Sleepers() >= want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// シミュレーター内の時間で limit が経過するか、done が true を返すまで時計を進める
+// 自動監視も監視ループも動いていない場合は待つものがないため終了する
+func (e *simEnv) run(limit time.Duration, done func() bool) {
+	start := e.clock.Now()
+	for e.clock.Since(start) < limit && !done() {
+		e.waitUntilIdle()
+		if _, ok := e.clock.AdvanceToNext(); !ok {
+			return
+		}
+	}
+}
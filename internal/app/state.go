@@ -0,0 +1,23 @@
+package app
+
+import "lol-auto-accept/internal/websocket"
+
+// 監視していないときの状態表示
+const statusStopped = "停止中"
+
+// 現在の監視状態（WebSocket接続時に送る）
+func (a *App) CurrentState() websocket.StateEvent {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return websocket.NewState(a.running, a.waitingForMatch, a.autoWatching, a.status)
+}
+
+// 状態表示を更新し、状態の変化を全クライアントに通知する
+func (a *App) publishState(status string) {
+	a.mutex.Lock()
+	a.status = status
+	a.mutex.Unlock()
+
+	a.wsManager.UpdateStatus(status)
+	a.wsManager.UpdateState(a.CurrentState())
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/templates"
+)
+
+var ErrPackInUse = errors.New("使用中のテンプレートパックは削除できません")
+
+// 設定ファイルのパス（テンプレートパックの切り替えを保存する、空の場合は保存しない）
+func (a *App) SetConfigPath(path string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.configPath = path
+}
+
+// 使用中のテンプレートパック名
+func (a *App) ActiveTemplatePack() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.activePack
+}
+
+// 使用中のテンプレートパックを検出器に読み込む（結果は準備状況の確認用に記録する）
+func (a *App) loadTemplates() error {
+	err := a.loadActivePack()
+	a.mutex.Lock()
+	a.templatesErr = err
+	a.mutex.Unlock()
+	return err
+}
+
+func (a *App) loadActivePack() error {
+	name := a.ActiveTemplatePack()
+	if name == templates.DefaultPack {
+		return a.detector.LoadTemplates()
+	}
+
+	_, accept, matching, err := a.templates.Load(name)
+	if err != nil {
+		return fmt.Errorf("テンプレートパック %s: %v", name, err)
+	}
+	a.detector.SetTemplates(accept, matching)
+	return nil
+}
+
+// テンプレートパックを切り替える（監視中でも次のフレームから新しいテンプレートで検出する）
+func (a *App) ActivateTemplatePack(name string) error {
+	_, accept, matching, err := a.templates.Load(name)
+	if err != nil {
+		return err
+	}
+	a.detector.SetTemplates(accept, matching)
+
+	a.mutex.Lock()
+	a.activePack = name
+	a.templatesErr = nil
+	configPath := a.configPath
+	a.mutex.Unlock()
+
+	a.log.Info("テンプレートパックを切り替えました", "pack", name)
+	if configPath == "" {
+		return nil
+	}
+	return config.Update(configPath, func(cfg *config.Config) {
+		cfg.Templates.Active = name
+	})
+}
+
+// テンプレートパックを保存する（使用中のパックの場合は検出器にも反映する）
+func (a *App) SaveTemplatePack(pack templates.Pack, accept, matching image.Image) error {
+	if pack.Created.IsZero() {
+		pack.Created = a.clock.Now()
+	}
+	if err := a.templates.Save(pack, accept, matching); err != nil {
+		return err
+	}
+	return a.reloadIfActive(pack.Name)
+}
+
+// パック内の1枚を差し替える（使用中のパックの場合は検出器にも反映する）
+func (a *App) SaveTemplateImage(name, file string, img image.Image) error {
+	if err := a.templates.SaveImage(name, file, img); err != nil {
+		return err
+	}
+	return a.reloadIfActive(name)
+}
+
+// テンプレートパックを削除する（使用中のパックは削除できない）
+func (a *App) DeleteTemplatePack(name string) error {
+	if name == a.ActiveTemplatePack() {
+		return ErrPackInUse
+	}
+	return a.templates.Delete(name)
+}
+
+func (a *App) reloadIfActive(name string) error {
+	if name != a.ActiveTemplatePack() {
+		return nil
+	}
+	if err := a.loadTemplates(); err != nil {
+		return err
+	}
+	a.log.Info("使用中のテンプレートパックを更新しました", "pack", name)
+	return nil
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+// 同じ種類のログを一定間隔に1回だけ送るための判定
+type logThrottle struct {
+	clock clock.Clock
+	mutex sync.Mutex
+	last  map[string]time.Time
+}
+
+func newLogThrottle(clk clock.Clock) *logThrottle {
+	return &logThrottle{clock: clk, last: make(map[string]time.Time)}
+}
+
+// key のログを送ってよければ true を返し、送信時刻を記録する
+func (t *logThrottle) Allow(key string, interval time.Duration) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	if last, ok := t.last[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	t.last[key] = now
+	return true
+}
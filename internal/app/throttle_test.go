@@ -0,0 +1,32 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+func TestLogThrottle(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	throttle := newLogThrottle(clk)
+
+	if !throttle.Allow("waiting_match", waitingLogInterval) {
+		t.Fatal("first log was throttled")
+	}
+	clk.Advance(waitingLogInterval - time.Millisecond)
+	if throttle.Allow("waiting_match", waitingLogInterval) {
+		t.Fatal("log allowed before the interval elapsed")
+	}
+	// 種類ごとに間隔を管理する
+	if !throttle.Allow("searching_accept", searchingLogInterval) {
+		t.Fatal("another key was throttled")
+	}
+	clk.Advance(time.Millisecond)
+	if !throttle.Allow("waiting_match", waitingLogInterval) {
+		t.Fatal("log throttled after the interval elapsed")
+	}
+	if throttle.Allow("waiting_match", waitingLogInterval) {
+		t.Fatal("interval did not restart after an allowed log")
+	}
+}
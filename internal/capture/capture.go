@@ -0,0 +1,100 @@
+package capture
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kbinani/screenshot"
+	"lol-auto-accept/internal/clock"
+)
+
+// 1回分のキャプチャ結果
+// FramePool から取得したフレームは参照カウントで管理され、利用後に Release が必要
+type Frame struct {
+	Image      *image.RGBA     // 取得した画像（Bounds はクライアント座標系で、Client の一部の場合がある）
+	Client     image.Rectangle // クライアント領域全体（Min は常に (0,0)）
+	Origin     image.Point     // クライアント領域左上のスクリーン座標
+	FromWindow bool            // クライアントウィンドウから取得した場合は true（ディスプレイ全体の場合は false）
+	// ウィンドウ検出に対応した環境でクライアントウィンドウが見つからなかった場合は true
+	// （検出に対応していない環境でディスプレイ全体を取得した場合は false）
+	ClientMissing bool
+
+	refs atomic.Int32
+	pool *FramePool
+}
+
+// 既存の画像から（プールを使わない）全体フレームを作成
+func NewFrame(img *image.RGBA) *Frame {
+	return &Frame{Image: img, Client: img.Bounds()}
+}
+
+// クライアント領域全体を取得したフレームかどうか
+func (f *Frame) IsFull() bool {
+	return f.Image.Bounds() == f.Client
+}
+
+// 画像内の座標をスクリーン座標に変換
+func (f *Frame) ToScreen(p image.Point) image.Point {
+	return p.Add(f.Origin)
+}
+
+// キャプチャ元
+type Source interface {
+	// region で指定した部分だけを取得する（Full でクライアント全体）
+	Capture(region Region) (*Frame, error)
+}
+
+// スクリーン上の矩形 screenRect のうち region 部分だけをプールのバッファに取得
+func captureRegion(pool *FramePool, g *grabber, screenRect image.Rectangle, region Region) (*Frame, error) {
+	client := image.Rect(0, 0, screenRect.Dx(), screenRect.Dy())
+	roi := region.Rect(client)
+	if roi.Empty() {
+		roi = client
+	}
+
+	frame := pool.Get(roi)
+	if err := g.grab(frame.Image, roi.Add(screenRect.Min)); err != nil {
+		frame.Release()
+		return nil, err
+	}
+	frame.Client = client
+	frame.Origin = screenRect.Min
+	return frame, nil
+}
+
+// プライマリディスプレイ全体をキャプチャ
+type DisplaySource struct {
+	pool    *FramePool
+	grabber *grabber
+	clock   clock.Clock
+
+	mutex     sync.Mutex
+	bounds    image.Rectangle
+	lastCheck time.Time
+}
+
+func NewDisplaySource(clk clock.Clock) *DisplaySource {
+	return &DisplaySource{
+		pool:    NewFramePool(),
+		grabber: newGrabber(),
+		clock:   clk,
+	}
+}
+
+func (s *DisplaySource) Capture(region Region) (*Frame, error) {
+	return captureRegion(s.pool, s.grabber, s.displayBounds(), region)
+}
+
+// ディスプレイの範囲（一定間隔でのみ再取得）
+func (s *DisplaySource) displayBounds() image.Rectangle {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.bounds.Empty() || s.clock.Since(s.lastCheck) >= windowRefreshInterval {
+		s.bounds = screenshot.GetDisplayBounds(0)
+		s.lastCheck = s.clock.Now()
+	}
+	return s.bounds
+}
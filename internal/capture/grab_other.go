@@ -0,0 +1,29 @@
+//go:build !(linux || freebsd || openbsd || netbsd)
+
+package capture
+
+import (
+	"image"
+
+	"github.com/kbinani/screenshot"
+)
+
+// 画面の取得（screenshot パッケージで取得した画像をバッファにコピー）
+type grabber struct{}
+
+func newGrabber() *grabber {
+	return &grabber{}
+}
+
+func (g *grabber) grab(dst *image.RGBA, screenRect image.Rectangle) error {
+	img, err := screenshot.CaptureRect(screenRect)
+	if err != nil {
+		return err
+	}
+
+	rowBytes := 4 * screenRect.Dx()
+	for y := 0; y < screenRect.Dy(); y++ {
+		copy(dst.Pix[y*dst.Stride:y*dst.Stride+rowBytes], img.Pix[y*img.Stride:y*img.Stride+rowBytes])
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package capture
+
+import (
+	"image"
+	"sync"
+
+	"github.com/gen2brain/shm"
+	"github.com/jezek/xgb"
+	mshm "github.com/jezek/xgb/shm"
+	"github.com/jezek/xgb/xinerama"
+	"github.com/jezek/xgb/xproto"
+)
+
+// X11 からの画面取得
+// 接続と共有メモリを使い回し、呼び出し側のバッファに直接書き込む
+type grabber struct {
+	mutex  sync.Mutex
+	conn   *xgb.Conn
+	root   xproto.Window
+	screen image.Rectangle // ルートウィンドウ全体
+	offset image.Point     // プライマリディスプレイの原点
+
+	useShm  bool
+	seg     mshm.Seg
+	shmId   int
+	shmData []byte
+}
+
+func newGrabber() *grabber {
+	return &grabber{}
+}
+
+func (g *grabber) connect() error {
+	if g.conn != nil {
+		return nil
+	}
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+
+	var offset image.Point
+	if err := xinerama.Init(conn); err == nil {
+		if reply, err := xinerama.QueryScreens(conn).Reply(); err == nil && len(reply.ScreenInfo) > 0 {
+			offset = image.Pt(int(reply.ScreenInfo[0].XOrg), int(reply.ScreenInfo[0].YOrg))
+		}
+	}
+
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	g.conn = conn
+	g.root = screen.Root
+	g.screen = image.Rect(0, 0, int(screen.WidthInPixels), int(screen.HeightInPixels))
+	g.offset = offset
+	g.useShm = mshm.Init(conn) == nil
+	return nil
+}
+
+// 接続と共有メモリを破棄（次回の取得時に再接続する）
+func (g *grabber) reset() {
+	g.releaseShm()
+	if g.conn != nil {
+		g.conn.Close()
+	}
+	g.conn = nil
+}
+
+// 必要な大きさの共有メモリを確保（足りない場合のみ作り直す）
+func (g *grabber) ensureShm(size int) error {
+	if len(g.shmData) >= size {
+		return nil
+	}
+	g.releaseShm()
+
+	shmId, err := shm.Get(shm.IPC_PRIVATE, size, shm.IPC_CREAT|0600)
+	if err != nil {
+		return err
+	}
+	data, err := shm.At(shmId, 0, 0)
+	if err != nil {
+		shm.Rm(shmId)
+		return err
+	}
+	seg, err := mshm.NewSegId(g.conn)
+	if err != nil {
+		shm.Dt(data)
+		shm.Rm(shmId)
+		return err
+	}
+	if err := mshm.AttachChecked(g.conn, seg, uint32(shmId), false).Check(); err != nil {
+		shm.Dt(data)
+		shm.Rm(shmId)
+		return err
+	}
+
+	g.seg = seg
+	g.shmId = shmId
+	g.shmData = data
+	return nil
+}
+
+func (g *grabber) releaseShm() {
+	if g.shmData == nil {
+		return
+	}
+	if g.conn != nil {
+		mshm.Detach(g.conn, g.seg)
+	}
+	shm.Dt(g.shmData)
+	shm.Rm(g.shmId)
+	g.shmData = nil
+}
+
+// screenRect（プライマリディスプレイ基準の座標）を dst に取得
+func (g *grabber) grab(dst *image.RGBA, screenRect image.Rectangle) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.connect(); err != nil {
+		return err
+	}
+
+	target := screenRect.Add(g.offset)
+	intersect := g.screen.Intersect(target)
+
+	// 画面外の部分は不透明な黒で塗りつぶす
+	if intersect != target {
+		for i := 0; i < len(dst.Pix); i += 4 {
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = 0, 0, 0, 255
+		}
+	}
+	if intersect.Empty() {
+		return nil
+	}
+
+	data, err := g.fetch(intersect)
+	if err != nil {
+		g.reset()
+		return err
+	}
+
+	// BGRA から RGBA に変換しながらコピー
+	min := dst.Rect.Min.Add(intersect.Min.Sub(target.Min))
+	offset := 0
+	for y := 0; y < intersect.Dy(); y++ {
+		i := dst.PixOffset(min.X, min.Y+y)
+		for x := 0; x < intersect.Dx(); x++ {
+			dst.Pix[i] = data[offset+2]
+			dst.Pix[i+1] = data[offset+1]
+			dst.Pix[i+2] = data[offset]
+			dst.Pix[i+3] = 255
+			i += 4
+			offset += 4
+		}
+	}
+	return nil
+}
+
+// ルートウィンドウの rect 部分の画素（BGRA）を取得
+func (g *grabber) fetch(rect image.Rectangle) ([]byte, error) {
+	size := 4 * rect.Dx() * rect.Dy()
+
+	if g.useShm {
+		if err := g.ensureShm(size); err == nil {
+			_, err := mshm.GetImage(g.conn, xproto.Drawable(g.root),
+				int16(rect.Min.X), int16(rect.Min.Y), uint16(rect.Dx()), uint16(rect.Dy()),
+				0xffffffff, byte(xproto.ImageFormatZPixmap), g.seg, 0).Reply()
+			if err == nil {
+				return g.shmData[:size], nil
+			}
+		}
+		// 共有メモリが使えない場合は通常の取得に切り替える
+		g.releaseShm()
+		g.useShm = false
+	}
+
+	reply, err := xproto.GetImage(g.conn, xproto.ImageFormatZPixmap, xproto.Drawable(g.root),
+		int16(rect.Min.X), int16(rect.Min.Y), uint16(rect.Dx()), uint16(rect.Dy()), 0xffffffff).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return reply.Data, nil
+}
@@ -0,0 +1,116 @@
+package capture
+
+import (
+	"image"
+	"sync"
+)
+
+// プールに保持しておくバッファの最大数
+const maxPooledFrames = 8
+
+// フレームバッファを使い回すためのプール
+// Get で取得したフレームは参照カウント 1 で返され、最後の Release でプールに戻る
+type FramePool struct {
+	mutex sync.Mutex
+	free  []*Frame
+}
+
+func NewFramePool() *FramePool {
+	return &FramePool{}
+}
+
+// rect の大きさの画像を持つフレームを取得（十分な容量のバッファがあれば再利用）
+func (p *FramePool) Get(rect image.Rectangle) *Frame {
+	size := 4 * rect.Dx() * rect.Dy()
+
+	p.mutex.Lock()
+	var frame *Frame
+	for i, f := range p.free {
+		if cap(f.Image.Pix) >= size {
+			frame = f
+			p.free = append(p.free[:i], p.free[i+1:]...)
+			break
+		}
+	}
+	p.mutex.Unlock()
+
+	if frame == nil {
+		frame = &Frame{Image: &image.RGBA{Pix: make([]uint8, size)}, pool: p}
+	}
+
+	frame.Image.Pix = frame.Image.Pix[:size]
+	frame.Image.Stride = 4 * rect.Dx()
+	frame.Image.Rect = rect
+	frame.Client = image.Rectangle{}
+	frame.Origin = image.Point{}
+	frame.FromWindow = false
+	frame.ClientMissing = false
+	frame.refs.Store(1)
+	return frame
+}
+
+func (p *FramePool) put(frame *Frame) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.free) < maxPooledFrames {
+		p.free = append(p.free, frame)
+	}
+}
+
+// 参照カウントを増やす（別の利用者に渡す前に呼び出す）
+func (f *Frame) Retain() *Frame {
+	if f.pool != nil {
+		f.refs.Add(1)
+	}
+	return f
+}
+
+// 参照カウントを減らし、0 になったらバッファをプールに戻す
+func (f *Frame) Release() {
+	if f.pool == nil {
+		return
+	}
+	refs := f.refs.Add(-1)
+	if refs == 0 {
+		f.pool.put(f)
+	} else if refs < 0 {
+		panic("capture: Frame released too many times")
+	}
+}
+
+// 最新フレームを保持する（デバッグ表示などの非同期な利用者向け）
+type LatestFrame struct {
+	mutex sync.Mutex
+	frame *Frame
+}
+
+// 新しいフレームを保持し、以前のフレームを解放する
+func (l *LatestFrame) Set(frame *Frame) {
+	if frame != nil {
+		frame.Retain()
+	}
+
+	l.mutex.Lock()
+	old := l.frame
+	l.frame = frame
+	l.mutex.Unlock()
+
+	if old != nil {
+		old.Release()
+	}
+}
+
+// 保持しているフレームを参照カウント付きで取得（利用後に Release が必要）
+func (l *LatestFrame) Acquire() *Frame {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.frame == nil {
+		return nil
+	}
+	return l.frame.Retain()
+}
+
+// 保持しているフレームを解放
+func (l *LatestFrame) Clear() {
+	l.Set(nil)
+}
@@ -0,0 +1,69 @@
+package capture
+
+import (
+	"image"
+	"testing"
+)
+
+// キャプチャ1回分のフレームの受け渡し（画面の取得を除く）
+// 監視ループと同じく、取得したフレームを LatestFrame に保持してから手放す
+func cycle(pool *FramePool, latest *LatestFrame, rect image.Rectangle) {
+	frame := pool.Get(rect)
+	latest.Set(frame)
+	frame.Release()
+}
+
+func TestFramePoolReuse(t *testing.T) {
+	pool := NewFramePool()
+	frame := pool.Get(image.Rect(0, 0, 64, 36))
+	pix := &frame.Image.Pix[0]
+	frame.Release()
+
+	// 小さい範囲の取得では同じバッファを使い回す
+	again := pool.Get(image.Rect(0, 0, 32, 18))
+	if &again.Image.Pix[0] != pix {
+		t.Error("released buffer was not reused")
+	}
+	if got, want := len(again.Image.Pix), 4*32*18; got != want {
+		t.Errorf("len(Pix) = %d; want %d", got, want)
+	}
+	if got, want := again.Image.Stride, 4*32; got != want {
+		t.Errorf("Stride = %d; want %d", got, want)
+	}
+	again.Release()
+}
+
+func TestFramePoolAllocs(t *testing.T) {
+	pool := NewFramePool()
+	latest := &LatestFrame{}
+	full := image.Rect(0, 0, 1280, 720)
+	roi := image.Rect(0, 360, 1280, 720)
+
+	// 最初の数回でバッファを確保した後は割り当てが発生しない
+	for i := 0; i < maxPooledFrames; i++ {
+		cycle(pool, latest, full)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		cycle(pool, latest, full)
+		cycle(pool, latest, roi)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per cycle = %.1f; want 0", allocs)
+	}
+	latest.Clear()
+}
+
+func BenchmarkFramePool(b *testing.B) {
+	pool := NewFramePool()
+	latest := &LatestFrame{}
+	rect := image.Rect(0, 0, 1280, 720)
+	cycle(pool, latest, rect)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cycle(pool, latest, rect)
+	}
+	b.StopTimer()
+	latest.Clear()
+}
@@ -0,0 +1,79 @@
+package capture
+
+import (
+	"image"
+	"sync"
+)
+
+// クライアント領域に対する割合で表した矩形
+type Region struct {
+	Left, Top, Right, Bottom float64
+}
+
+// クライアント領域全体
+var Full = Region{Left: 0, Top: 0, Right: 1, Bottom: 1}
+
+// クライアント領域内のピクセル矩形に変換（領域外ははみ出さないように切り詰める）
+func (r Region) Rect(client image.Rectangle) image.Rectangle {
+	w := float64(client.Dx())
+	h := float64(client.Dy())
+	rect := image.Rect(
+		client.Min.X+int(r.Left*w), client.Min.Y+int(r.Top*h),
+		client.Min.X+int(r.Right*w), client.Min.Y+int(r.Bottom*h),
+	)
+	return rect.Intersect(client)
+}
+
+// ピクセル矩形をクライアント領域に対する割合に変換
+func RegionOf(rect, client image.Rectangle) Region {
+	w := float64(client.Dx())
+	h := float64(client.Dy())
+	if w == 0 || h == 0 {
+		return Full
+	}
+	return Region{
+		Left:   float64(rect.Min.X-client.Min.X) / w,
+		Top:    float64(rect.Min.Y-client.Min.Y) / h,
+		Right:  float64(rect.Max.X-client.Min.X) / w,
+		Bottom: float64(rect.Max.Y-client.Min.Y) / h,
+	}
+}
+
+// 複数の領域をすべて含む最小の領域
+func Union(regions ...Region) Region {
+	if len(regions) == 0 {
+		return Full
+	}
+
+	u := regions[0]
+	for _, r := range regions[1:] {
+		u.Left = min(u.Left, r.Left)
+		u.Top = min(u.Top, r.Top)
+		u.Right = max(u.Right, r.Right)
+		u.Bottom = max(u.Bottom, r.Bottom)
+	}
+	return u
+}
+
+// 毎回のキャプチャ範囲を決める
+// 通常は検出器が使う領域の和だけを取得し、一定回数ごとに全体を取得して位置を再特定する
+type RegionPlanner struct {
+	fullEvery int
+	count     int
+	mutex     sync.Mutex
+}
+
+func NewRegionPlanner(fullEvery int) *RegionPlanner {
+	return &RegionPlanner{fullEvery: fullEvery}
+}
+
+func (p *RegionPlanner) Next(regions ...Region) Region {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.count++
+	if p.fullEvery <= 1 || p.count%p.fullEvery == 1 {
+		return Full
+	}
+	return Union(regions...)
+}
@@ -0,0 +1,81 @@
+package capture
+
+import (
+	"image"
+	"testing"
+)
+
+func TestRegionRect(t *testing.T) {
+	client := image.Rect(100, 50, 1380, 770) // 1280x720
+	tests := []struct {
+		name   string
+		region Region
+		want   image.Rectangle
+	}{
+		{"full", Full, client},
+		{"bottom center", Region{Left: 0.25, Top: 0.5, Right: 0.75, Bottom: 1}, image.Rect(420, 410, 1060, 770)},
+		{"outside", Region{Left: -0.5, Top: -0.1, Right: 1.5, Bottom: 1.2}, client},
+		{"partly outside", Region{Left: 0.5, Top: 0.5, Right: 1.5, Bottom: 1.5}, image.Rect(740, 410, 1380, 770)},
+	}
+	for _, tt := range tests {
+		if got := tt.region.Rect(client); got != tt.want {
+			t.Errorf("%s: Rect = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	a := Region{Left: 0.1, Top: 0.2, Right: 0.4, Bottom: 0.5}
+	b := Region{Left: 0.3, Top: 0.6, Right: 0.7, Bottom: 0.9}
+	if got, want := Union(a, b), (Region{Left: 0.1, Top: 0.2, Right: 0.7, Bottom: 0.9}); got != want {
+		t.Errorf("Union(a, b) = %+v; want %+v", got, want)
+	}
+	if got := Union(a); got != a {
+		t.Errorf("Union(a) = %+v; want %+v", got, a)
+	}
+	if got := Union(); got != Full {
+		t.Errorf("Union() = %+v; want Full", got)
+	}
+
+	// はみ出した領域の和も、取得範囲は画面内に収まる
+	client := image.Rect(0, 0, 1920, 1080)
+	out := Union(Region{Left: -0.2, Top: 0.5, Right: 0.5, Bottom: 1.3}, Region{Left: 0.4, Top: -0.1, Right: 1.1, Bottom: 0.6})
+	if got := out.Rect(client); got != client {
+		t.Errorf("Union(outside).Rect = %v; want %v", got, client)
+	}
+}
+
+func TestRegionPlanner(t *testing.T) {
+	roi := Region{Left: 0.25, Top: 0.5, Right: 0.75, Bottom: 1}
+	p := NewRegionPlanner(4)
+	var got []Region
+	for i := 0; i < 9; i++ {
+		got = append(got, p.Next(roi))
+	}
+	// 1回目と、その後4回ごとに全体を取得する
+	want := []Region{Full, roi, roi, roi, Full, roi, roi, roi, Full}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Next #%d = %+v; want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestRegionPlannerFallsBackToFull(t *testing.T) {
+	// 検出器が領域を指定しない場合は全体
+	p := NewRegionPlanner(4)
+	p.Next()
+	if got := p.Next(); got != Full {
+		t.Errorf("Next() without regions = %+v; want Full", got)
+	}
+
+	// 間隔が1以下なら毎回全体
+	for _, every := range []int{0, 1} {
+		p := NewRegionPlanner(every)
+		for i := 0; i < 3; i++ {
+			if got := p.Next(Region{Right: 0.5, Bottom: 0.5}); got != Full {
+				t.Errorf("NewRegionPlanner(%d).Next #%d = %+v; want Full", every, i+1, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package capture
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+// ウィンドウを再探索する間隔
+const windowRefreshInterval = 5 * time.Second
+
+var (
+	ErrWindowNotFound    = errors.New("クライアントウィンドウが見つかりません")
+	ErrWindowUnsupported = errors.New("この環境ではウィンドウ検出に対応していません")
+)
+
+// League クライアントのウィンドウ領域だけをキャプチャ
+// ウィンドウが見つからない場合はディスプレイ全体にフォールバックする
+type WindowSource struct {
+	title    string
+	finder   *windowFinder
+	fallback *DisplaySource
+	clock    clock.Clock
+
+	mutex     sync.Mutex
+	rect      image.Rectangle
+	found     bool
+	supported bool // この環境でウィンドウ検出が使えるか
+	lastCheck time.Time
+}
+
+func NewWindowSource(title string, clk clock.Clock) *WindowSource {
+	return &WindowSource{
+		title:    title,
+		finder:   newWindowFinder(),
+		fallback: NewDisplaySource(clk),
+		clock:    clk,
+	}
+}
+
+// 現在のクライアントウィンドウ領域（スクリーン座標）
+func (s *WindowSource) WindowRect() (image.Rectangle, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.rect, s.found
+}
+
+func (s *WindowSource) Capture(region Region) (*Frame, error) {
+	rect, ok, supported := s.locate()
+	if !ok {
+		frame, err := s.fallback.Capture(region)
+		if err != nil {
+			return nil, err
+		}
+		frame.ClientMissing = supported
+		return frame, nil
+	}
+
+	frame, err := captureRegion(s.fallback.pool, s.fallback.grabber, rect, region)
+	if err != nil {
+		return nil, err
+	}
+	frame.FromWindow = true
+	return frame, nil
+}
+
+// クライアントウィンドウの位置と、ウィンドウ検出に対応しているかを取得（一定間隔でのみ再探索）
+func (s *WindowSource) locate() (image.Rectangle, bool, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.clock.Since(s.lastCheck) < windowRefreshInterval {
+		return s.rect, s.found, s.supported
+	}
+	s.lastCheck = s.clock.Now()
+
+	rect, err := s.finder.find(s.title)
+	s.rect = rect
+	s.found = err == nil && !rect.Empty()
+	s.supported = !errors.Is(err, ErrWindowUnsupported)
+	return s.rect, s.found, s.supported
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || freebsd || openbsd || netbsd)
+
+package capture
+
+import "image"
+
+type windowFinder struct{}
+
+func newWindowFinder() *windowFinder {
+	return &windowFinder{}
+}
+
+func (f *windowFinder) find(title string) (image.Rectangle, error) {
+	return image.Rectangle{}, ErrWindowUnsupported
+}
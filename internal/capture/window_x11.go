@@ -0,0 +1,150 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package capture
+
+import (
+	"encoding/binary"
+	"image"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xinerama"
+	"github.com/jezek/xgb/xproto"
+)
+
+// EWMH (_NET_CLIENT_LIST / _NET_WM_NAME) を使ったウィンドウ検索
+type windowFinder struct {
+	conn   *xgb.Conn
+	root   xproto.Window
+	atoms  map[string]xproto.Atom
+	offset image.Point // プライマリディスプレイの原点（スクリーンショット座標への変換用）
+	window xproto.Window
+}
+
+func newWindowFinder() *windowFinder {
+	return &windowFinder{}
+}
+
+func (f *windowFinder) connect() error {
+	if f.conn != nil {
+		return nil
+	}
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+
+	atoms := make(map[string]xproto.Atom)
+	for _, name := range []string{"_NET_CLIENT_LIST", "_NET_WM_NAME", "UTF8_STRING"} {
+		reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		atoms[name] = reply.Atom
+	}
+
+	// スクリーンショット座標はプライマリディスプレイ基準のため原点を取得
+	var offset image.Point
+	if err := xinerama.Init(conn); err == nil {
+		if reply, err := xinerama.QueryScreens(conn).Reply(); err == nil && len(reply.ScreenInfo) > 0 {
+			offset = image.Pt(int(reply.ScreenInfo[0].XOrg), int(reply.ScreenInfo[0].YOrg))
+		}
+	}
+
+	f.conn = conn
+	f.root = xproto.Setup(conn).DefaultScreen(conn).Root
+	f.atoms = atoms
+	f.offset = offset
+	return nil
+}
+
+func (f *windowFinder) reset() {
+	if f.conn != nil {
+		f.conn.Close()
+	}
+	f.conn = nil
+	f.window = 0
+}
+
+// タイトルが一致するウィンドウの領域を返す
+func (f *windowFinder) find(title string) (image.Rectangle, error) {
+	if err := f.connect(); err != nil {
+		return image.Rectangle{}, err
+	}
+
+	// 前回見つかったウィンドウがまだ存在すればそのまま使う
+	if f.window != 0 && strings.TrimSpace(f.windowTitle(f.window)) == title {
+		if rect, err := f.windowRect(f.window); err == nil {
+			return rect, nil
+		}
+	}
+	f.window = 0
+
+	windows, err := f.clientList()
+	if err != nil {
+		f.reset()
+		return image.Rectangle{}, err
+	}
+
+	for _, w := range windows {
+		if strings.TrimSpace(f.windowTitle(w)) != title {
+			continue
+		}
+		rect, err := f.windowRect(w)
+		if err != nil || rect.Empty() {
+			continue
+		}
+		f.window = w
+		return rect, nil
+	}
+
+	return image.Rectangle{}, ErrWindowNotFound
+}
+
+func (f *windowFinder) clientList() ([]xproto.Window, error) {
+	reply, err := xproto.GetProperty(f.conn, false, f.root, f.atoms["_NET_CLIENT_LIST"],
+		xproto.AtomWindow, 0, 1<<16).Reply()
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]xproto.Window, 0, reply.ValueLen)
+	for i := 0; i+4 <= len(reply.Value) && len(windows) < int(reply.ValueLen); i += 4 {
+		windows = append(windows, xproto.Window(binary.LittleEndian.Uint32(reply.Value[i:])))
+	}
+	return windows, nil
+}
+
+// _NET_WM_NAME (UTF-8) を優先し、なければ WM_NAME を使う
+func (f *windowFinder) windowTitle(w xproto.Window) string {
+	reply, err := xproto.GetProperty(f.conn, false, w, f.atoms["_NET_WM_NAME"],
+		f.atoms["UTF8_STRING"], 0, 1024).Reply()
+	if err == nil && reply.ValueLen > 0 {
+		return string(reply.Value)
+	}
+
+	reply, err = xproto.GetProperty(f.conn, false, w, xproto.AtomWmName,
+		xproto.AtomString, 0, 1024).Reply()
+	if err == nil && reply.ValueLen > 0 {
+		return string(reply.Value)
+	}
+	return ""
+}
+
+// ウィンドウの領域（スクリーンショット座標）
+func (f *windowFinder) windowRect(w xproto.Window) (image.Rectangle, error) {
+	geom, err := xproto.GetGeometry(f.conn, xproto.Drawable(w)).Reply()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+
+	pos, err := xproto.TranslateCoordinates(f.conn, w, f.root, 0, 0).Reply()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+
+	min := image.Pt(int(pos.DstX), int(pos.DstY)).Sub(f.offset)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(int(geom.Width), int(geom.Height)))}, nil
+}
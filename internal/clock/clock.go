@@ -0,0 +1,112 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 時刻の取得と待機（テストでは Fake に差し替えて時間を進める）
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) Sleep(d time.Duration)           { time.Sleep(d) }
+
+// 実時間の時計
+var Real Clock = realClock{}
+
+// Advance で進めるまで時間が止まっている時計
+// Sleep は時計が起床時刻まで進められるまでブロックする
+type Fake struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	until time.Time
+	done  chan struct{}
+}
+
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+func (c *Fake) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *Fake) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *Fake) Sleep(d time.Duration) {
+	c.mutex.Lock()
+	if d <= 0 {
+		c.mutex.Unlock()
+		return
+	}
+	w := &waiter{until: c.now.Add(d), done: make(chan struct{})}
+	c.waiters = append(c.waiters, w)
+	c.mutex.Unlock()
+
+	<-w.done
+}
+
+// 時計を d だけ進め、起床時刻を過ぎた Sleep を返す
+func (c *Fake) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// 最も早く起床する Sleep の時刻まで進める（待機中の Sleep がなければ false）
+func (c *Fake) AdvanceToNext() (time.Duration, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.waiters) == 0 {
+		return 0, false
+	}
+	sort.Slice(c.waiters, func(i, j int) bool {
+		return c.waiters[i].until.Before(c.waiters[j].until)
+	})
+	step := c.waiters[0].until.Sub(c.now)
+	c.setLocked(c.waiters[0].until)
+	return step, true
+}
+
+// Sleep でブロックしているゴルーチンの数
+func (c *Fake) Sleepers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.waiters)
+}
+
+// Sleep しているゴルーチンが n 個になるまで待つ
+func (c *Fake) BlockUntil(n int) {
+	for c.Sleepers() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (c *Fake) setLocked(now time.Time) {
+	c.now = now
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if now.Before(w.until) {
+			pending = append(pending, w)
+		} else {
+			close(w.done)
+		}
+	}
+	c.waiters = pending
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// 設定ファイルの既定パス
+const DefaultPath = "config.json"
+
+type Config struct {
+	DryRun    bool            `json:"dry_run"`
+	Capture   CaptureConfig   `json:"capture"`
+	Client    ClientConfig    `json:"client"`
+	Mouse     MouseConfig     `json:"mouse"`
+	Debug     DebugConfig     `json:"debug"`
+	Templates TemplatesConfig `json:"templates"`
+	Log       LogConfig       `json:"log"`
+	History   HistoryConfig   `json:"history"`
+	Server    ServerConfig    `json:"server"`
+}
+
+// 操作用サーバーの設定（token が空の場合は起動時に生成して保存する）
+// tls が有効で cert_file と key_file がない場合は自己署名証明書を生成する
+type ServerConfig struct {
+	Address  string `json:"address"`
+	Token    string `json:"token"`
+	TLS      bool   `json:"tls"`
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// レディチェックの履歴の設定（file が空の場合は保存しない）
+type HistoryConfig struct {
+	File string `json:"file"`
+}
+
+// ログの設定（file が空の場合はファイルに出力しない）
+type LogConfig struct {
+	Level     string `json:"level"` // debug / info / warn / error
+	File      string `json:"file"`
+	MaxSizeMB int    `json:"max_size_mb"`
+	MaxFiles  int    `json:"max_files"`
+}
+
+// テンプレートパックの設定（active が空または "default" の場合は同梱のテンプレート）
+type TemplatesConfig struct {
+	Dir    string `json:"dir"`
+	Active string `json:"active"`
+}
+
+// デバッグ用スナップショットの設定
+type DebugConfig struct {
+	Snapshots    bool   `json:"snapshots"`
+	Dir          string `json:"dir"`
+	MaxSnapshots int    `json:"max_snapshots"`
+}
+
+// キャプチャ対象の設定（window_title が空の場合はディスプレイ全体）
+type CaptureConfig struct {
+	WindowTitle string `json:"window_title"`
+}
+
+// クライアントの設定（lockfile が空の場合は OS ごとの既定の場所、Linux では既定の場所がないため確認しない）
+type ClientConfig struct {
+	Lockfile string `json:"lockfile"`
+}
+
+// マウス移動の設定（humanize=false の場合は従来通り瞬時に移動）
+type MouseConfig struct {
+	Humanize   bool    `json:"humanize"`
+	DurationMs int     `json:"duration_ms"`
+	Easing     string  `json:"easing"`
+	Jitter     float64 `json:"jitter"`
+}
+
+func Default() *Config {
+	return &Config{
+		DryRun: false,
+		Capture: CaptureConfig{
+			WindowTitle: "League of Legends",
+		},
+		Mouse: MouseConfig{
+			Humanize:   false,
+			DurationMs: 250,
+			Easing:     "ease-in-out",
+			Jitter:     0.3,
+		},
+		Debug: DebugConfig{
+			Snapshots:    false,
+			Dir:          "debug",
+			MaxSnapshots: 50,
+		},
+		Templates: TemplatesConfig{
+			Dir:    "templates",
+			Active: "default",
+		},
+		Log: LogConfig{
+			Level:     "info",
+			File:      "logs/lol-auto-accept.log",
+			MaxSizeMB: 10,
+			MaxFiles:  3,
+		},
+		History: HistoryConfig{
+			File: "history/ready_checks.jsonl",
+		},
+		Server: ServerConfig{
+			Address:  "127.0.0.1:8081",
+			CertFile: "certs/server.crt",
+			KeyFile:  "certs/server.key",
+		},
+	}
+}
+
+// 操作用サーバーのトークンを生成する
+func GenerateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("トークンの生成失敗: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// 設定ファイルを読み込む（ファイルが存在しない場合は既定値を返す）
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルの読み込み失敗: %v", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("設定ファイルの解析失敗: %v", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("設定のシリアライズ失敗: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("設定ファイルの書き込み失敗: %v", err)
+	}
+	return nil
+}
+
+// 設定ファイルを読み込み直して fn で変更し、書き戻す（起動時のフラグによる上書きは保存しない）
+func Update(path string, fn func(cfg *Config)) error {
+	cfg, err := Load(path)
+	if err != nil {
+		return err
+	}
+	fn(cfg)
+	return cfg.Save(path)
+}
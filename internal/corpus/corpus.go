@@ -0,0 +1,119 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 承認ボタンの正解範囲（クライアント座標）
+type Box struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+func (b Box) Rect() image.Rectangle {
+	return image.Rect(b.X, b.Y, b.X+b.W, b.Y+b.H)
+}
+
+// 1フレーム分の正解ラベル（PNGと同名のJSONファイル）
+type Label struct {
+	Matching bool   `json:"matching"`       // マッチング画面が表示されているか
+	Accept   *Box   `json:"accept"`         // 承認ボタンの範囲（表示されていない場合は null）
+	Note     string `json:"note,omitempty"` // 解像度・言語などのメモ
+}
+
+// ラベル付きのフレーム
+type Case struct {
+	Name  string
+	Image *image.RGBA
+	Label Label
+}
+
+// ディレクトリ内の *.png と対応する *.json を読み込む
+func Load(dir string) ([]Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("コーパスの読み込み失敗: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".png"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	cases := make([]Case, 0, len(names))
+	for _, name := range names {
+		c, err := loadCase(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+func loadCase(dir, name string) (Case, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if err != nil {
+		return Case{}, fmt.Errorf("ラベルの読み込み失敗 (%s): %v", name, err)
+	}
+	var label Label
+	if err := json.Unmarshal(data, &label); err != nil {
+		return Case{}, fmt.Errorf("ラベルの解析失敗 (%s): %v", name, err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, name+".png"))
+	if err != nil {
+		return Case{}, fmt.Errorf("画像の読み込み失敗 (%s): %v", name, err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return Case{}, fmt.Errorf("画像のデコード失敗 (%s): %v", name, err)
+	}
+
+	return Case{Name: name, Image: toRGBA(img), Label: label}, nil
+}
+
+// 検出器が扱える (0,0) 起点の RGBA 画像に変換
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// ラベル付きフレームを書き出す（生成ツールなどから利用）
+func Save(dir string, c Case) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, c.Name+".png"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := png.Encode(file, c.Image); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c.Label, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, c.Name+".json"), data, 0644)
+}
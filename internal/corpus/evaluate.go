@@ -0,0 +1,138 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/detector"
+)
+
+// 精度指標を比較するときの許容誤差
+const regressionTolerance = 0.001
+
+// 二値判定の集計
+type Confusion struct {
+	TruePositive  int `json:"tp"`
+	FalsePositive int `json:"fp"`
+	FalseNegative int `json:"fn"`
+	TrueNegative  int `json:"tn"`
+}
+
+// 検出したもののうち正しかった割合（検出がない場合は 1）
+func (c Confusion) Precision() float64 {
+	if c.TruePositive+c.FalsePositive == 0 {
+		return 1
+	}
+	return float64(c.TruePositive) / float64(c.TruePositive+c.FalsePositive)
+}
+
+// 検出すべきもののうち検出できた割合（対象がない場合は 1）
+func (c Confusion) Recall() float64 {
+	if c.TruePositive+c.FalseNegative == 0 {
+		return 1
+	}
+	return float64(c.TruePositive) / float64(c.TruePositive+c.FalseNegative)
+}
+
+// フレームごとの判定ミス
+type Failure struct {
+	Case   string `json:"case"`
+	Kind   string `json:"kind"` // "matching" / "accept"
+	Detail string `json:"detail"`
+}
+
+// コーパス全体の評価結果
+type Report struct {
+	Cases    int       `json:"cases"`
+	Matching Confusion `json:"matching"`
+	Accept   Confusion `json:"accept"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// 各フレームで FastDetectMatchingScreen と FastDetectAcceptButton を実行して集計
+// 前のフレームの結果のキャッシュやヒントが影響しないよう、フレームごとに検出器を複製する
+func Evaluate(d *detector.ImageDetector, cases []Case) Report {
+	report := Report{Cases: len(cases)}
+
+	for _, c := range cases {
+		frame := capture.NewFrame(c.Image)
+		d := d.Clone()
+
+		matching := d.FastDetectMatchingScreen(frame)
+		switch {
+		case matching && c.Label.Matching:
+			report.Matching.TruePositive++
+		case matching && !c.Label.Matching:
+			report.Matching.FalsePositive++
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Kind: "matching", Detail: "誤検出"})
+		case !matching && c.Label.Matching:
+			report.Matching.FalseNegative++
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Kind: "matching", Detail: "未検出"})
+		default:
+			report.Matching.TrueNegative++
+		}
+
+		pos := d.FastDetectAcceptButton(frame)
+		switch {
+		case pos != nil && c.Label.Accept != nil:
+			if image.Pt(pos.X, pos.Y).In(c.Label.Accept.Rect()) {
+				report.Accept.TruePositive++
+			} else {
+				// 位置違いは誤検出かつ未検出として扱う
+				report.Accept.FalsePositive++
+				report.Accept.FalseNegative++
+				report.Failures = append(report.Failures, Failure{Case: c.Name, Kind: "accept",
+					Detail: fmt.Sprintf("位置違い (%d, %d)", pos.X, pos.Y)})
+			}
+		case pos != nil:
+			report.Accept.FalsePositive++
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Kind: "accept",
+				Detail: fmt.Sprintf("誤検出 (%d, %d)", pos.X, pos.Y)})
+		case c.Label.Accept != nil:
+			report.Accept.FalseNegative++
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Kind: "accept", Detail: "未検出"})
+		default:
+			report.Accept.TrueNegative++
+		}
+	}
+
+	return report
+}
+
+// 基準となる評価結果より悪化した指標の一覧（悪化がなければ空）
+func (r Report) Regressions(baseline Report) []string {
+	var regressions []string
+	check := func(name string, current, base float64) {
+		if current < base-regressionTolerance {
+			regressions = append(regressions, fmt.Sprintf("%s: %.3f → %.3f", name, base, current))
+		}
+	}
+	check("マッチング画面 precision", r.Matching.Precision(), baseline.Matching.Precision())
+	check("マッチング画面 recall", r.Matching.Recall(), baseline.Matching.Recall())
+	check("承認ボタン precision", r.Accept.Precision(), baseline.Accept.Precision())
+	check("承認ボタン recall", r.Accept.Recall(), baseline.Accept.Recall())
+	return regressions
+}
+
+func LoadReport(path string) (Report, error) {
+	var report Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("評価結果の解析失敗: %v", err)
+	}
+	return report, nil
+}
+
+func (r Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
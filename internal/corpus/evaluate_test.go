@@ -0,0 +1,86 @@
+package corpus
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lol-auto-accept/internal/detector"
+)
+
+// リポジトリのルート（テスト実行時のカレントディレクトリはパッケージのディレクトリ）
+const repoRoot = "../.."
+
+func loadTemplate(t *testing.T, name string) image.Image {
+	t.Helper()
+	file, err := os.Open(filepath.Join(repoRoot, "resources", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// testdata/corpus のフレームを評価し、baseline.json より悪化していないことを確認する
+func TestCorpusBaseline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("detection over the corpus is slow")
+	}
+
+	dir := filepath.Join(repoRoot, "testdata", "corpus")
+	cases, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no frames in %s", dir)
+	}
+	baseline, err := LoadReport(filepath.Join(dir, "baseline.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := detector.NewImageDetector()
+	d.SetTemplates(loadTemplate(t, "accept_button.png"), loadTemplate(t, "matching.png"))
+
+	report := Evaluate(d, cases)
+	if report.Cases != baseline.Cases {
+		t.Errorf("cases = %d; want %d", report.Cases, baseline.Cases)
+	}
+	for _, f := range report.Failures {
+		t.Logf("NG %s [%s] %s", f.Case, f.Kind, f.Detail)
+	}
+	for _, r := range report.Regressions(baseline) {
+		t.Errorf("regression: %s", r)
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	baseline := Report{
+		Matching: Confusion{TruePositive: 2, TrueNegative: 1},
+		Accept:   Confusion{TruePositive: 2, FalsePositive: 1},
+	}
+
+	if got := baseline.Regressions(baseline); len(got) != 0 {
+		t.Errorf("regressions against itself = %v; want none", got)
+	}
+
+	improved := Report{Accept: Confusion{TruePositive: 2, TrueNegative: 1}, Matching: baseline.Matching}
+	if got := improved.Regressions(baseline); len(got) != 0 {
+		t.Errorf("regressions after improving = %v; want none", got)
+	}
+
+	worse := Report{
+		Matching: Confusion{TruePositive: 1, FalseNegative: 1, TrueNegative: 1},
+		Accept:   baseline.Accept,
+	}
+	if got := worse.Regressions(baseline); len(got) != 1 {
+		t.Errorf("regressions = %v; want the recall drop only", got)
+	}
+}
@@ -3,68 +3,100 @@ package detector
 import (
 	"image"
 	"image/color"
+
+	"lol-auto-accept/internal/capture"
 )
 
 // 高精度承認ボタン検出（複数手法併用）
-func (d *ImageDetector) FastDetectAcceptButton(img *image.RGBA) *Point {
-	bounds := img.Bounds()
-	centerX := bounds.Dx() / 2
-	centerY := bounds.Dy() / 2
-	
-	// より広い検索範囲（画面下部全体）
-	searchArea := image.Rect(
-		centerX-400, centerY-50,
-		centerX+400, centerY+250,
-	)
-	
-	// 境界チェック
-	if searchArea.Min.X < 0 {
-		searchArea.Min.X = 0
-	}
-	if searchArea.Min.Y < 0 {
-		searchArea.Min.Y = 0
-	}
-	if searchArea.Max.X > bounds.Dx() {
-		searchArea.Max.X = bounds.Dx()
-	}
-	if searchArea.Max.Y > bounds.Dy() {
-		searchArea.Max.Y = bounds.Dy()
+func (d *ImageDetector) FastDetectAcceptButton(frame *capture.Frame) *Point {
+	pos, _ := d.DetectAcceptButton(frame)
+	return pos
+}
+
+// FastDetectAcceptButton と同じ検出を行い、前回の結果を使ったかも返す
+func (d *ImageDetector) DetectAcceptButton(frame *capture.Frame) (*Point, bool) {
+	// 検索範囲はクライアント領域に対する割合で指定
+	searchArea := searchRect(frame, AcceptSearchRegion)
+	
+	// 呼び出し中はテンプレートの差し替えの影響を受けないよう最初に取得
+	t := d.currentTemplates()
+	
+	// 前回から見た目が変わっていなければ前回の結果を返す
+	sig, cached, ok := d.acceptCache.lookup(t, frame.Image, searchArea)
+	if ok {
+		d.stats.skipped.Add(1)
+		return cached, true
 	}
+	d.stats.processed.Add(1)
 	
-	// 手法1: テンプレートマッチング（複数スケール・低閾値）
-	scales := []float64{0.5, 0.6, 0.7, 0.8, 0.9, 1.0, 1.1, 1.2, 1.3, 1.5}
-	thresholds := []float64{0.4, 0.5, 0.6, 0.7}
-	
+	result := d.detectAcceptButton(t, frame.Image, searchArea, nil)
+	d.acceptCache.store(t, searchArea, sig, result)
+	return result, false
+}
+
+// FastDetectAcceptButton と同じ検出を行い、候補の一覧も返す（キャッシュは使わない）
+func (d *ImageDetector) TraceAcceptButton(frame *capture.Frame) (*Point, *Trace) {
+	searchArea := searchRect(frame, AcceptSearchRegion)
+	trace := &Trace{SearchArea: searchArea}
+	
+	d.stats.processed.Add(1)
+	trace.Result = d.detectAcceptButton(d.currentTemplates(), frame.Image, searchArea, trace)
+	return trace.Result, trace
+}
+
+func (d *ImageDetector) detectAcceptButton(t *templateSet, img *image.RGBA, searchArea image.Rectangle, trace *Trace) *Point {
 	var bestMatch *Point
 	var bestScore float64
 	
-	for _, threshold := range thresholds {
-		for _, scale := range scales {
-			if pos := d.templateMatchFast(img, d.acceptTemplate, threshold, searchArea, scale); pos != nil {
-				score := d.VerifyAcceptButton(img, pos, scale)
-				if score > bestScore {
-					bestScore = score
-					bestMatch = pos
+	// 手法1: テンプレートマッチング（複数スケール・低閾値）
+	if t.accept != nil {
+		scales := []float64{0.5, 0.6, 0.7, 0.8, 0.9, 1.0, 1.1, 1.2, 1.3, 1.5}
+		thresholds := []float64{0.4, 0.5, 0.6, 0.7}
+		
+		for _, threshold := range thresholds {
+			for _, scale := range scales {
+				if pos := d.templateMatchFast(img, t.accept, threshold, searchArea, scale); pos != nil {
+					score := d.verifyAcceptButton(t, img, pos, scale)
+					trace.add(Candidate{
+						Method: MethodTemplate,
+						Box:    pos.Box,
+						Score:  score,
+						Scale:  scale,
+					})
+					if score > bestScore {
+						bestScore = score
+						bestMatch = pos
+					}
 				}
 			}
 		}
 	}
 	
+	if bestMatch != nil {
+		bestMatch.Method = MethodTemplate
+	}
+	
 	// 手法2: 色ベース検出（青緑のボタン色を検出）
 	if bestMatch == nil {
-		bestMatch = d.detectButtonByColor(img, searchArea)
+		bestMatch = d.detectButtonByColor(img, searchArea, trace)
+		if bestMatch != nil {
+			bestMatch.Method = MethodColor
+		}
 	}
 	
 	// 手法3: エッジ検出（ボタンの輪郭を検出）
 	if bestMatch == nil {
-		bestMatch = d.detectButtonByEdge(img, searchArea)
+		bestMatch = d.detectButtonByEdge(img, searchArea, trace)
+		if bestMatch != nil {
+			bestMatch.Method = MethodEdge
+		}
 	}
 	
 	return bestMatch
 }
 
 // 色ベース検出（青緑のボタン色を検出）
-func (d *ImageDetector) detectButtonByColor(img *image.RGBA, searchArea image.Rectangle) *Point {
+func (d *ImageDetector) detectButtonByColor(img *image.RGBA, searchArea image.Rectangle, trace *Trace) *Point {
 	var bestCandidate *Point
 	maxClusterSize := 0
 	
@@ -85,6 +117,16 @@ func (d *ImageDetector) detectButtonByColor(img *image.RGBA, searchArea image.Re
 		}
 	}
 	
+	if bestCandidate != nil {
+		bestCandidate.Box = boxAround(bestCandidate, 2*colorClusterRadius+1, 2*colorClusterRadius+1)
+		// スコアはクラスタ範囲に占める類似色ピクセルの割合
+		trace.add(Candidate{
+			Method: MethodColor,
+			Box:    bestCandidate.Box,
+			Score:  float64(maxClusterSize) / float64((2*colorClusterRadius+1)*(2*colorClusterRadius+1)),
+		})
+	}
+	
 	return bestCandidate
 }
 
@@ -96,10 +138,13 @@ func (d *ImageDetector) isAcceptButtonColor(c color.RGBA) bool {
 		   (c.G > 120 && c.B > 80 && c.R < 100)              // 青緑色
 }
 
+// 類似色クラスタを数える範囲の半径
+const colorClusterRadius = 20
+
 // 類似色クラスタのサイズをカウント
 func (d *ImageDetector) countSimilarColorCluster(img *image.RGBA, centerX, centerY int, bounds image.Rectangle) int {
 	count := 0
-	radius := 20
+	radius := colorClusterRadius
 	
 	for dy := -radius; dy <= radius; dy++ {
 		for dx := -radius; dx <= radius; dx++ {
@@ -119,13 +164,15 @@ func (d *ImageDetector) countSimilarColorCluster(img *image.RGBA, centerX, cente
 }
 
 // エッジ検出による承認ボタン検出
-func (d *ImageDetector) detectButtonByEdge(img *image.RGBA, searchArea image.Rectangle) *Point {
+func (d *ImageDetector) detectButtonByEdge(img *image.RGBA, searchArea image.Rectangle, trace *Trace) *Point {
 	// ボタンの矩形エッジを検出
 	for y := searchArea.Min.Y; y < searchArea.Max.Y-50; y += 5 {
 		for x := searchArea.Min.X; x < searchArea.Max.X-100; x += 5 {
 			// 50x25のエリアでボタンらしい形状を検索
-			if d.isButtonShape(img, x, y, 100, 50) {
-				return &Point{X: x + 50, Y: y + 25} // 中心点を返す
+			if density := d.edgeDensity(img, x, y, 100, 50); density > 0.15 {
+				box := image.Rect(x, y, x+100, y+50)
+				trace.add(Candidate{Method: MethodEdge, Box: box, Score: density})
+				return &Point{X: x + 50, Y: y + 25, Box: box} // 中心点を返す
 			}
 		}
 	}
@@ -133,11 +180,11 @@ func (d *ImageDetector) detectButtonByEdge(img *image.RGBA, searchArea image.Rec
 	return nil
 }
 
-// ボタンの形状判定
-func (d *ImageDetector) isButtonShape(img *image.RGBA, x, y, width, height int) bool {
+// ボタンの形状判定用のエッジ密度（範囲外の場合は 0）
+func (d *ImageDetector) edgeDensity(img *image.RGBA, x, y, width, height int) float64 {
 	bounds := img.Bounds()
 	if x+width >= bounds.Max.X || y+height >= bounds.Max.Y {
-		return false
+		return 0
 	}
 	
 	edgeCount := 0
@@ -164,7 +211,10 @@ func (d *ImageDetector) isButtonShape(img *image.RGBA, x, y, width, height int)
 	}
 	
 	// エッジの密度でボタン判定
-	return totalPixels > 0 && float64(edgeCount)/float64(totalPixels) > 0.15
+	if totalPixels == 0 {
+		return 0
+	}
+	return float64(edgeCount) / float64(totalPixels)
 }
 
 // 色の差分計算
@@ -188,11 +238,15 @@ func (d *ImageDetector) colorDifference(c1, c2 color.RGBA) int {
 
 // 承認ボタンの詳細検証（より緩い条件）
 func (d *ImageDetector) VerifyAcceptButton(img *image.RGBA, pos *Point, scale float64) float64 {
-	if d.acceptTemplate == nil {
+	return d.verifyAcceptButton(d.currentTemplates(), img, pos, scale)
+}
+
+func (d *ImageDetector) verifyAcceptButton(t *templateSet, img *image.RGBA, pos *Point, scale float64) float64 {
+	if t.accept == nil {
 		return 0.5 // テンプレートがない場合でも基本スコアを返す
 	}
 	
-	needleBounds := d.acceptTemplate.Bounds()
+	needleBounds := t.accept.Bounds()
 	needleWidth := int(float64(needleBounds.Dx()) * scale)
 	needleHeight := int(float64(needleBounds.Dy()) * scale)
 	
@@ -201,12 +255,13 @@ func (d *ImageDetector) VerifyAcceptButton(img *image.RGBA, pos *Point, scale fl
 	startY := pos.Y - needleHeight/2
 	
 	// 境界チェック
-	if startX < 0 || startY < 0 || startX+needleWidth >= img.Bounds().Max.X || startY+needleHeight >= img.Bounds().Max.Y {
+	imgBounds := img.Bounds()
+	if startX < imgBounds.Min.X || startY < imgBounds.Min.Y || startX+needleWidth >= imgBounds.Max.X || startY+needleHeight >= imgBounds.Max.Y {
 		return 0.3 // 境界外でも低いスコアで継続
 	}
 	
 	// より詳細な類似度計算
-	score := d.calculateDetailedSimilarity(img, d.acceptTemplate, startX, startY, scale)
+	score := d.calculateDetailedSimilarity(img, t.accept, startX, startY, scale)
 	
 	// 周囲の色も考慮してスコアを調整
 	colorBonus := d.checkSurroundingColors(img, pos.X, pos.Y)
@@ -225,7 +280,7 @@ func (d *ImageDetector) checkSurroundingColors(img *image.RGBA, centerX, centerY
 			x := centerX + dx
 			y := centerY + dy
 			
-			if x >= 0 && x < bounds.Dx() && y >= 0 && y < bounds.Dy() {
+			if image.Pt(x, y).In(bounds) {
 				c := img.RGBAAt(x, y)
 				if d.isAcceptButtonColor(c) {
 					blueGreenCount++
@@ -305,4 +360,18 @@ func (d *ImageDetector) colorsAreSimilarStrict(c1 color.RGBA, r2, g2, b2 uint32)
 	}
 	
 	return (dr + dg + db) < 60 // より厳しい閾値
-}
\ No newline at end of file
+}
+
+// 承認ボタンの範囲（検出時に一致した範囲、不明な場合は検出位置を中心とした等倍のテンプレートの範囲）
+func (d *ImageDetector) AcceptButtonBounds(pos *Point) image.Rectangle {
+	if !pos.Box.Empty() {
+		return pos.Box
+	}
+	accept := d.currentTemplates().accept
+	if accept == nil {
+		return image.Rect(pos.X, pos.Y, pos.X+1, pos.Y+1)
+	}
+
+	needleBounds := accept.Bounds()
+	return boxAround(pos, needleBounds.Dx(), needleBounds.Dy())
+}
@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"lol-auto-accept/internal/capture"
+)
+
+// 2色の縦縞（背景と取り違えない配色）
+func stripes(w, h, width int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 230, G: 200, B: 40, A: 255}
+			if (x/width)%2 == 1 {
+				c = color.RGBA{R: 20, G: 90, B: 200, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// 半分の大きさで表示されたボタンは、半分の大きさの範囲をクリック対象にする
+func TestAcceptButtonBoundsUsesMatchedScale(t *testing.T) {
+	template := stripes(48, 24, 8)
+	frameImg := image.NewRGBA(image.Rect(0, 0, 320, 180))
+	draw.Draw(frameImg, frameImg.Bounds(), &image.Uniform{C: color.RGBA{R: 1, G: 10, B: 19, A: 255}}, image.Point{}, draw.Src)
+	button := image.Rect(150, 120, 174, 132)
+	draw.Draw(frameImg, button, stripes(24, 12, 4), image.Point{}, draw.Src)
+
+	d := NewImageDetector()
+	d.SetTemplates(template, nil)
+	pos := d.FastDetectAcceptButton(capture.NewFrame(frameImg))
+	if pos == nil {
+		t.Fatal("accept button not detected")
+	}
+	if pos.Method != MethodTemplate {
+		t.Fatalf("method = %s; want %s", pos.Method, MethodTemplate)
+	}
+
+	bounds := d.AcceptButtonBounds(pos)
+	if bounds.Dx() != button.Dx() || bounds.Dy() != button.Dy() {
+		t.Errorf("bounds = %v; want the size of %v", bounds, button)
+	}
+	if overlap := bounds.Intersect(button); overlap.Dx()*overlap.Dy() < button.Dx()*button.Dy()/2 {
+		t.Errorf("bounds = %v; want mostly inside %v", bounds, button)
+	}
+}
+
+// 一致した範囲が分からない場合は等倍のテンプレートの大きさ
+func TestAcceptButtonBoundsFallback(t *testing.T) {
+	d := NewImageDetector()
+	d.SetTemplates(stripes(48, 24, 8), nil)
+	bounds := d.AcceptButtonBounds(&Point{X: 100, Y: 50})
+	if want := image.Rect(76, 38, 124, 62); bounds != want {
+		t.Errorf("bounds = %v; want %v", bounds, want)
+	}
+}
+
+// 複製した検出器は元の検出器の結果キャッシュを使わない
+func TestCloneDoesNotShareCache(t *testing.T) {
+	frameImg := image.NewRGBA(image.Rect(0, 0, 320, 180))
+	draw.Draw(frameImg, frameImg.Bounds(), &image.Uniform{C: color.RGBA{R: 1, G: 10, B: 19, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(frameImg, image.Rect(136, 108, 184, 132), stripes(48, 24, 8), image.Point{}, draw.Src)
+	frame := capture.NewFrame(frameImg)
+
+	d := NewImageDetector()
+	d.SetTemplates(stripes(48, 24, 8), nil)
+	if _, cached := d.DetectAcceptButton(frame); cached {
+		t.Fatal("first detection cached = true; want false")
+	}
+	if _, cached := d.DetectAcceptButton(frame); !cached {
+		t.Fatal("second detection cached = false; want true")
+	}
+
+	pos, cached := d.Clone().DetectAcceptButton(frame)
+	if cached {
+		t.Error("clone detection cached = true; want false")
+	}
+	if pos == nil {
+		t.Error("clone did not detect the accept button")
+	}
+}
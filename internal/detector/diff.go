@@ -0,0 +1,125 @@
+package detector
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// 差分判定に使うブロックの分割数（縦横それぞれ）
+	diffGridSize = 16
+	// ブロック内のサンプリング間隔
+	diffSampleStep = 4
+	// ブロックの平均輝度がこれ以下の差なら変化なしとみなす
+	diffThreshold = 3
+)
+
+// 検出処理の統計
+type Stats struct {
+	FramesProcessed uint64 `json:"frames_processed"`
+	FramesSkipped   uint64 `json:"frames_skipped"`
+}
+
+type detectorStats struct {
+	processed atomic.Uint64
+	skipped   atomic.Uint64
+}
+
+func (d *ImageDetector) Stats() Stats {
+	return Stats{
+		FramesProcessed: d.stats.processed.Load(),
+		FramesSkipped:   d.stats.skipped.Load(),
+	}
+}
+
+// 各ブロックの平均輝度を並べたもの
+type signature [diffGridSize * diffGridSize]uint8
+
+// シグネチャ用バッファの使い回し
+var signaturePool = sync.Pool{
+	New: func() any {
+		return new(signature)
+	},
+}
+
+// 検索領域の見た目が前回と変わっていなければ前回の検出結果を再利用するためのキャッシュ
+type resultCache[T any] struct {
+	mutex     sync.Mutex
+	templates *templateSet
+	rect      image.Rectangle
+	signature *signature
+	result    T
+	valid     bool
+}
+
+// 前回と同じテンプレート・同じ領域・同じ見た目なら前回の結果を返す
+// 一致しなかった場合に返すシグネチャは store に渡すこと
+func (c *resultCache[T]) lookup(t *templateSet, img *image.RGBA, rect image.Rectangle) (*signature, T, bool) {
+	sig := signaturePool.Get().(*signature)
+	sig.compute(img, rect)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.valid && c.templates == t && c.rect == rect && c.signature.matches(sig) {
+		signaturePool.Put(sig)
+		return nil, c.result, true
+	}
+	var zero T
+	return sig, zero, false
+}
+
+func (c *resultCache[T]) store(t *templateSet, rect image.Rectangle, sig *signature, result T) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.signature != nil {
+		signaturePool.Put(c.signature)
+	}
+	c.templates = t
+	c.rect = rect
+	c.signature = sig
+	c.result = result
+	c.valid = true
+}
+
+// 領域をブロックに分割し、各ブロックの平均輝度を求める
+func (s *signature) compute(img *image.RGBA, rect image.Rectangle) {
+	rect = rect.Intersect(img.Bounds())
+	*s = signature{}
+	if rect.Empty() {
+		return
+	}
+
+	for by := 0; by < diffGridSize; by++ {
+		y0 := rect.Min.Y + rect.Dy()*by/diffGridSize
+		y1 := rect.Min.Y + rect.Dy()*(by+1)/diffGridSize
+		for bx := 0; bx < diffGridSize; bx++ {
+			x0 := rect.Min.X + rect.Dx()*bx/diffGridSize
+			x1 := rect.Min.X + rect.Dx()*(bx+1)/diffGridSize
+
+			sum, count := 0, 0
+			for y := y0; y < y1; y += diffSampleStep {
+				i := img.PixOffset(x0, y)
+				for x := x0; x < x1; x += diffSampleStep {
+					// 輝度の近似値 (2R + 5G + B) / 8
+					sum += (2*int(img.Pix[i]) + 5*int(img.Pix[i+1]) + int(img.Pix[i+2])) >> 3
+					count++
+					i += 4 * diffSampleStep
+				}
+			}
+			if count > 0 {
+				s[by*diffGridSize+bx] = uint8(sum / count)
+			}
+		}
+	}
+}
+
+func (s *signature) matches(other *signature) bool {
+	for i := range s {
+		diff := int(s[i]) - int(other[i])
+		if diff > diffThreshold || diff < -diffThreshold {
+			return false
+		}
+	}
+	return true
+}
@@ -5,19 +5,47 @@ import (
 	"image"
 	"image/png"
 	"os"
+	"sync"
+	"sync/atomic"
 
-	"github.com/kbinani/screenshot"
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/logging"
 )
 
 type Point struct {
-	X, Y int
+	X, Y   int
+	Method string          // 承認ボタンを検出した手法（MethodTemplate / MethodColor / MethodEdge）
+	Box    image.Rectangle // 一致した範囲（テンプレートの場合は一致したスケールでの大きさ）
 }
 
+// 承認ボタンの検出手法
+const (
+	MethodTemplate = "template"
+	MethodColor    = "color"
+	MethodEdge     = "edge"
+	MethodText     = "text" // マッチング画面の文字の色（マッチング画面の検出のみ）
+)
+
+// 読み込み済みのテンプレート一式（読み込み後は変更しない）
+type templateSet struct {
+	accept   image.Image
+	matching image.Image
+}
+
+// 複数のゴルーチン（自動監視・監視ループ・環境テスト）から同時に呼び出せる
 type ImageDetector struct {
-	acceptTemplate   image.Image
-	matchingTemplate image.Image
-	lastScreenshot   *image.RGBA
-	screenBounds     image.Rectangle
+	// テンプレートは丸ごと差し替える
+	templates atomic.Pointer[templateSet]
+
+	// 全体フレームで特定したマッチングテンプレートの位置
+	matchingHint    capture.Region
+	hasMatchingHint bool
+	hintMutex       sync.Mutex
+
+	// 変化のないフレームの検出をスキップするためのキャッシュ
+	matchingCache resultCache[string]
+	acceptCache   resultCache[*Point]
+	stats         detectorStats
 }
 
 func NewImageDetector() *ImageDetector {
@@ -36,7 +64,6 @@ func (d *ImageDetector) LoadTemplates() error {
 	if err != nil {
 		return fmt.Errorf("承認ボタン画像のデコード失敗: %v", err)
 	}
-	d.acceptTemplate = acceptImg
 
 	// マッチング画面のテンプレート読み込み
 	matchingFile, err := os.Open("resources/matching.png")
@@ -49,34 +76,54 @@ func (d *ImageDetector) LoadTemplates() error {
 	if err != nil {
 		return fmt.Errorf("マッチング画像のデコード失敗: %v", err)
 	}
-	d.matchingTemplate = matchingImg
 
+	d.SetTemplates(acceptImg, matchingImg)
 	return nil
 }
 
-func (d *ImageDetector) CaptureScreen() (*image.RGBA, error) {
-	bounds := screenshot.GetDisplayBounds(0)
-	img, err := screenshot.CaptureRect(bounds)
-	if err != nil {
-		return nil, err
+// テンプレートを差し替える（検出中の呼び出しは差し替え前のテンプレートで完了する）
+// 差し替え前のテンプレートで特定した位置は使わない
+func (d *ImageDetector) SetTemplates(accept, matching image.Image) {
+	d.templates.Store(&templateSet{accept: accept, matching: matching})
+	logging.For(logging.ComponentDetector).Debug("テンプレートを設定しました",
+		"accept", sizeOf(accept), "matching", sizeOf(matching))
+
+	d.hintMutex.Lock()
+	d.hasMatchingHint = false
+	d.hintMutex.Unlock()
+}
+
+// 同じテンプレートを使う新しい検出器（結果のキャッシュと位置のヒントは引き継がない）
+func (d *ImageDetector) Clone() *ImageDetector {
+	clone := NewImageDetector()
+	if t := d.templates.Load(); t != nil {
+		clone.templates.Store(t)
 	}
-	d.lastScreenshot = img
-	d.screenBounds = bounds
-	return img, nil
+	return clone
 }
 
-func (d *ImageDetector) GetScreenBounds() image.Rectangle {
-	return d.screenBounds
+func sizeOf(img image.Image) string {
+	if img == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%dx%d", img.Bounds().Dx(), img.Bounds().Dy())
 }
 
-func (d *ImageDetector) GetLastScreenshot() *image.RGBA {
-	return d.lastScreenshot
+// テンプレート未読み込み時に使う空のセット
+var emptyTemplates = &templateSet{}
+
+// 現在のテンプレート一式（未読み込みの場合は空のセット）
+func (d *ImageDetector) currentTemplates() *templateSet {
+	if t := d.templates.Load(); t != nil {
+		return t
+	}
+	return emptyTemplates
 }
 
 func (d *ImageDetector) GetAcceptTemplate() image.Image {
-	return d.acceptTemplate
+	return d.currentTemplates().accept
 }
 
 func (d *ImageDetector) GetMatchingTemplate() image.Image {
-	return d.matchingTemplate
+	return d.currentTemplates().matching
 }
\ No newline at end of file
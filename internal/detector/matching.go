@@ -3,25 +3,62 @@ package detector
 import (
 	"image"
 	"image/color"
+
+	"lol-auto-accept/internal/capture"
 )
 
 // 高速マッチング画面検出（テンプレートマッチング + 特徴点ベース）
-func (d *ImageDetector) FastDetectMatchingScreen(img *image.RGBA) bool {
-	bounds := img.Bounds()
+func (d *ImageDetector) FastDetectMatchingScreen(frame *capture.Frame) bool {
+	method, _ := d.DetectMatchingScreen(frame)
+	return method != ""
+}
+
+// マッチング画面を検出した手法（検出しなかった場合は空）と、前回の結果を使ったかを返す
+func (d *ImageDetector) DetectMatchingScreen(frame *capture.Frame) (string, bool) {
+	// 呼び出し中はテンプレートの差し替えの影響を受けないよう最初に取得
+	t := d.currentTemplates()
+	
+	// 全体フレームではクライアント全体を検索して位置を再特定する
+	region := d.matchingTemplateRegion()
+	if frame.IsFull() {
+		region = capture.Full
+	}
+	searchArea := searchRect(frame, region)
+	
+	// 前回から見た目が変わっていなければ前回の結果を返す
+	diffArea := searchArea.Union(searchRect(frame, MatchingTextRegion))
+	sig, cached, ok := d.matchingCache.lookup(t, frame.Image, diffArea)
+	if ok {
+		d.stats.skipped.Add(1)
+		return cached, true
+	}
+	d.stats.processed.Add(1)
+	
+	result := d.detectMatchingScreen(t, frame, searchArea)
+	d.matchingCache.store(t, diffArea, sig, result)
+	return result, false
+}
+
+func (d *ImageDetector) detectMatchingScreen(t *templateSet, frame *capture.Frame, searchArea image.Rectangle) string {
+	img := frame.Image
 	
 	// 1. テンプレートマッチングによる検出
-	if d.matchingTemplate != nil {
-		// 画面全体でマッチングテンプレートを検索（低い閾値で）
-		searchArea := image.Rect(0, 0, bounds.Dx(), bounds.Dy()) // 全体を検索
-		if pos := d.templateMatchFast(img, d.matchingTemplate, 0.6, searchArea, 1.0); pos != nil {
-			return true
+	if t.matching != nil {
+		if pos := d.templateMatchFast(img, t.matching, 0.6, searchArea, 1.0); pos != nil {
+			if frame.IsFull() {
+				d.rememberMatchingPosition(t, frame, pos, 1.0)
+			}
+			return MethodTemplate
 		}
 		
 		// 複数スケールでも試行
 		scales := []float64{0.5, 0.7, 0.8, 1.2, 1.5, 2.0}
 		for _, scale := range scales {
-			if pos := d.templateMatchFast(img, d.matchingTemplate, 0.5, searchArea, scale); pos != nil {
-				return true
+			if pos := d.templateMatchFast(img, t.matching, 0.5, searchArea, scale); pos != nil {
+				if frame.IsFull() {
+					d.rememberMatchingPosition(t, frame, pos, scale)
+				}
+				return MethodTemplate
 			}
 		}
 	}
@@ -31,31 +68,12 @@ func (d *ImageDetector) FastDetectMatchingScreen(img *image.RGBA) bool {
 	matchingTextCount := 0
 	totalSamples := 0
 	
-	// 画面中央付近を重点的に検索
-	centerX := bounds.Dx() / 2
-	centerY := bounds.Dy() / 2
-	searchRect := image.Rect(
-		centerX-200, centerY-100,
-		centerX+200, centerY+100,
-	)
-	
-	// 境界チェック
-	if searchRect.Min.X < 0 {
-		searchRect.Min.X = 0
-	}
-	if searchRect.Min.Y < 0 {
-		searchRect.Min.Y = 0
-	}
-	if searchRect.Max.X > bounds.Dx() {
-		searchRect.Max.X = bounds.Dx()
-	}
-	if searchRect.Max.Y > bounds.Dy() {
-		searchRect.Max.Y = bounds.Dy()
-	}
+	// クライアント中央付近を重点的に検索
+	textArea := searchRect(frame, MatchingTextRegion)
 	
 	// 3ピクセルごとにサンプリング
-	for y := searchRect.Min.Y; y < searchRect.Max.Y; y += 3 {
-		for x := searchRect.Min.X; x < searchRect.Max.X; x += 3 {
+	for y := textArea.Min.Y; y < textArea.Max.Y; y += 3 {
+		for x := textArea.Min.X; x < textArea.Max.X; x += 3 {
 			c := img.RGBAAt(x, y)
 			totalSamples++
 			
@@ -70,11 +88,11 @@ func (d *ImageDetector) FastDetectMatchingScreen(img *image.RGBA) bool {
 	if totalSamples > 0 {
 		textRatio := float64(matchingTextCount) / float64(totalSamples)
 		if textRatio > 0.05 { // 5%以上の白い文字があれば検出
-			return true
+			return MethodText
 		}
 	}
 	
-	return false
+	return ""
 }
 
 // 高速テンプレートマッチング（最適化版）
@@ -109,7 +127,7 @@ func (d *ImageDetector) templateMatchFast(haystack *image.RGBA, needle image.Ima
 			score := d.calculateSimilarityFast(haystack, needle, x, y, scale)
 			if score > bestScore {
 				bestScore = score
-				bestMatch = &Point{X: x + needleWidth/2, Y: y + needleHeight/2}
+				bestMatch = &Point{X: x + needleWidth/2, Y: y + needleHeight/2, Box: image.Rect(x, y, x+needleWidth, y+needleHeight)}
 			}
 		}
 	}
@@ -0,0 +1,63 @@
+package detector
+
+import (
+	"image"
+
+	"lol-auto-accept/internal/capture"
+)
+
+// 検索領域はクライアント領域に対する割合で指定する
+// 値は 1280x720 のクライアントを基準に決めている
+var (
+	// 承認ボタンの検索領域（中央から左右400px、上50px〜下250px）
+	AcceptSearchRegion = capture.Region{Left: 0.1875, Top: 0.43, Right: 0.8125, Bottom: 0.85}
+	// 「対戦を検出中」の文字を探す領域（中央から左右200px、上下100px）
+	MatchingTextRegion = capture.Region{Left: 0.34375, Top: 0.36, Right: 0.65625, Bottom: 0.64}
+	// マッチングテンプレートの既定の検索領域（位置が特定されるまでは中央の帯を検索）
+	MatchingTemplateRegion = capture.Region{Left: 0.1, Top: 0.2, Right: 0.9, Bottom: 0.8}
+)
+
+// テンプレートの検出位置の周囲に確保する余白（テンプレートサイズに対する倍率）
+const matchingHintPadding = 1.0
+
+// フレーム内で検索する矩形（取得済みの範囲に切り詰める）
+func searchRect(frame *capture.Frame, region capture.Region) image.Rectangle {
+	return region.Rect(frame.Client).Intersect(frame.Image.Bounds())
+}
+
+// 現在の状態で検出器が参照する領域
+// waitingForMatch が false の場合は承認ボタンの領域も含める
+func (d *ImageDetector) ActiveRegions(waitingForMatch bool) []capture.Region {
+	regions := []capture.Region{MatchingTextRegion, d.matchingTemplateRegion()}
+	if !waitingForMatch {
+		regions = append(regions, AcceptSearchRegion)
+	}
+	return regions
+}
+
+// マッチングテンプレートを探す領域（前回の検出位置があればその周辺）
+func (d *ImageDetector) matchingTemplateRegion() capture.Region {
+	d.hintMutex.Lock()
+	defer d.hintMutex.Unlock()
+	if d.hasMatchingHint {
+		return d.matchingHint
+	}
+	return MatchingTemplateRegion
+}
+
+// 全体フレームでテンプレートが見つかった位置を記録
+func (d *ImageDetector) rememberMatchingPosition(t *templateSet, frame *capture.Frame, pos *Point, scale float64) {
+	if t.matching == nil {
+		return
+	}
+
+	needleBounds := t.matching.Bounds()
+	padX := int(float64(needleBounds.Dx()) * scale * (0.5 + matchingHintPadding))
+	padY := int(float64(needleBounds.Dy()) * scale * (0.5 + matchingHintPadding))
+	rect := image.Rect(pos.X-padX, pos.Y-padY, pos.X+padX, pos.Y+padY).Intersect(frame.Client)
+
+	d.hintMutex.Lock()
+	defer d.hintMutex.Unlock()
+	d.matchingHint = capture.RegionOf(rect, frame.Client)
+	d.hasMatchingHint = true
+}
@@ -0,0 +1,30 @@
+package detector
+
+import "image"
+
+// 検出過程で見つかった候補
+type Candidate struct {
+	Method string          `json:"method"` // "template" / "color" / "edge"
+	Box    image.Rectangle `json:"box"`
+	Score  float64         `json:"score"`
+	Scale  float64         `json:"scale,omitempty"`
+}
+
+// 承認ボタン検出の過程の記録（デバッグ用）
+type Trace struct {
+	SearchArea image.Rectangle `json:"search_area"`
+	Candidates []Candidate     `json:"candidates"`
+	Result     *Point          `json:"result"`
+}
+
+// 候補を記録（トレース不要の場合は nil のまま呼び出してよい）
+func (t *Trace) add(c Candidate) {
+	if t != nil {
+		t.Candidates = append(t.Candidates, c)
+	}
+}
+
+// 中心と大きさから候補の範囲を求める
+func boxAround(pos *Point, width, height int) image.Rectangle {
+	return image.Rect(pos.X-width/2, pos.Y-height/2, pos.X-width/2+width, pos.Y-height/2+height)
+}
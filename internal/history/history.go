@@ -0,0 +1,159 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// レディチェックの結果
+type Outcome string
+
+const (
+	OutcomeAccepted Outcome = "accepted" // 承認してチャンピオン選択に進んだ
+	OutcomeDeclined Outcome = "declined" // 承認したが他のプレイヤーが辞退した
+	OutcomeMissed   Outcome = "missed"   // 承認できないままレディチェックが終わった
+	OutcomeDodged   Outcome = "dodged"   // チャンピオン選択で誰かが抜けた
+	// クリックしたがチャンピオン選択に進まないままマッチング画面が消えた（レディチェックではない画面の誤検出）
+	OutcomeFalsePositive Outcome = "false_positive"
+)
+
+// 1回分のレディチェック
+type Record struct {
+	ID             string    `json:"id"`
+	Time           time.Time `json:"time"`              // 承認ボタンを最初に検出した時刻
+	Method         string    `json:"method"`            // 承認ボタンを検出した手法
+	Score          float64   `json:"score"`             // 検証スコア
+	TimeToAcceptMs int64     `json:"time_to_accept_ms"` // 最初の検出から最初のクリックまで（クリックしていない場合は 0）
+	Clicks         int       `json:"clicks"`
+	DryRun         bool      `json:"dry_run"`
+	Outcome        Outcome   `json:"outcome"`
+}
+
+func NewID(t time.Time) string {
+	return t.Format("20060102-150405.000")
+}
+
+// JSON-lines 形式で記録を保存する（1行1件、同じ ID の行は後のものが優先）
+type Store struct {
+	mutex   sync.Mutex
+	path    string
+	records []Record
+	index   map[string]int
+}
+
+// ファイルを読み込む（存在しない場合は空）
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, index: map[string]int{}}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("履歴ファイルの読み込み失敗: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.ID == "" {
+			// 書き込み途中で終了した行などは読み飛ばす
+			continue
+		}
+		lines++
+		s.set(r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("履歴ファイルの読み込み失敗: %v", err)
+	}
+
+	// 更新で重複した行が多ければ書き直す
+	if lines > 2*len(s.records) && len(s.records) > 0 {
+		if err := s.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) set(r Record) {
+	if i, ok := s.index[r.ID]; ok {
+		s.records[i] = r
+		return
+	}
+	s.index[r.ID] = len(s.records)
+	s.records = append(s.records, r)
+}
+
+// 記録を追加・更新する
+func (s *Store) Put(r Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("履歴ディレクトリの作成失敗: %v", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("履歴ファイルの書き込み失敗: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("履歴ファイルの書き込み失敗: %v", err)
+	}
+
+	s.set(r)
+	return nil
+}
+
+func (s *Store) rewrite() error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("履歴ファイルの書き込み失敗: %v", err)
+	}
+	w := bufio.NewWriter(file)
+	for _, r := range s.records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("履歴ファイルの書き込み失敗: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("履歴ファイルの書き込み失敗: %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// 新しい順に最大 limit 件（0 以下の場合はすべて）
+func (s *Store) Records(limit int) []Record {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]Record, len(s.records))
+	copy(result, s.records)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.After(result[j].Time) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// 1日分の承認数（日付はローカル時刻）
+type DayCount struct {
+	Date    string `json:"date"`
+	Accepts int    `json:"accepts"`
+}
+
+// ドライランの記録は実際にクリックしていないため、DryRun の件数以外には含めない
+// 誤検出の記録はレディチェックではないため、FalsePositiveClicks 以外には含めない
+type Stats struct {
+	Total               int             `json:"total"`
+	DryRun              int             `json:"dry_run"` // 集計から除いたドライランの記録数
+	Outcomes            map[Outcome]int `json:"outcomes"`
+	AcceptsPerDay       []DayCount      `json:"accepts_per_day"`       // 古い順（missed 以外）
+	MedianReactionMs    int64           `json:"median_reaction_ms"`    // クリックしたレディチェックの中央値
+	MissRate            float64         `json:"miss_rate"`             // missed / total
+	RepeatClicks        int             `json:"repeat_clicks"`         // 1回のレディチェックで2回目以降のクリック
+	FalsePositiveClicks int             `json:"false_positive_clicks"` // レディチェックではない画面へのクリック
+}
+
+// 全期間の集計と、now を含む直近 days 日の日別の承認数
+func (s *Store) Stats(now time.Time, days int) Stats {
+	records := s.Records(0)
+
+	stats := Stats{
+		Outcomes: map[Outcome]int{OutcomeAccepted: 0, OutcomeDeclined: 0, OutcomeMissed: 0, OutcomeDodged: 0},
+	}
+
+	perDay := map[string]int{}
+	var reactions []int64
+	for _, r := range records {
+		if r.DryRun {
+			stats.DryRun++
+			continue
+		}
+		if r.Outcome == OutcomeFalsePositive {
+			stats.FalsePositiveClicks += r.Clicks
+			continue
+		}
+		stats.Total++
+		stats.Outcomes[r.Outcome]++
+		if r.Clicks > 1 {
+			stats.RepeatClicks += r.Clicks - 1
+		}
+		if r.Clicks > 0 {
+			reactions = append(reactions, r.TimeToAcceptMs)
+		}
+		// 他のプレイヤーの辞退などで試合にならなかった場合も、承認したものとして数える
+		if r.Outcome != OutcomeMissed {
+			perDay[r.Time.In(now.Location()).Format("2006-01-02")]++
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.MissRate = float64(stats.Outcomes[OutcomeMissed]) / float64(stats.Total)
+	}
+	stats.MedianReactionMs = median(reactions)
+
+	for i := days - 1; i >= 0; i-- {
+		date := now.AddDate(0, 0, -i).Format("2006-01-02")
+		stats.AcceptsPerDay = append(stats.AcceptsPerDay, DayCount{Date: date, Accepts: perDay[date]})
+	}
+	return stats
+}
+
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
@@ -0,0 +1,59 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatsExcludesDryRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	records := []Record{
+		{Time: now.Add(-3 * time.Hour), TimeToAcceptMs: 400, Clicks: 1, Outcome: OutcomeAccepted},
+		{Time: now.Add(-2 * time.Hour), TimeToAcceptMs: 800, Clicks: 3, Outcome: OutcomeDeclined},
+		{Time: now.Add(-time.Hour), Outcome: OutcomeMissed},
+		{Time: now.Add(-time.Minute), TimeToAcceptMs: 10, Clicks: 2, DryRun: true, Outcome: OutcomeAccepted},
+		{Time: now.Add(-30 * time.Minute), TimeToAcceptMs: 50, Clicks: 1, Outcome: OutcomeFalsePositive},
+		{Time: now.Add(-20 * time.Minute), TimeToAcceptMs: 70, Clicks: 2, Outcome: OutcomeFalsePositive},
+		{Time: now.Add(-10 * time.Minute), Clicks: 4, DryRun: true, Outcome: OutcomeFalsePositive},
+	}
+	for i, r := range records {
+		r.ID = NewID(r.Time.Add(time.Duration(i)))
+		if err := store.Put(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := store.Stats(now, 2)
+	if stats.Total != 3 {
+		t.Errorf("total = %d; want 3", stats.Total)
+	}
+	if stats.DryRun != 2 {
+		t.Errorf("dry_run = %d; want 2", stats.DryRun)
+	}
+	if stats.FalsePositiveClicks != 3 {
+		t.Errorf("false_positive_clicks = %d; want 3", stats.FalsePositiveClicks)
+	}
+	if n := stats.Outcomes[OutcomeFalsePositive]; n != 0 {
+		t.Errorf("outcomes[false_positive] = %d; want 0 (not a ready check)", n)
+	}
+	if stats.Outcomes[OutcomeAccepted] != 1 {
+		t.Errorf("accepted = %d; want 1", stats.Outcomes[OutcomeAccepted])
+	}
+	if stats.MedianReactionMs != 600 {
+		t.Errorf("median = %d; want 600", stats.MedianReactionMs)
+	}
+	if stats.MissRate != 1.0/3 {
+		t.Errorf("miss rate = %f; want 1/3", stats.MissRate)
+	}
+	if stats.RepeatClicks != 2 {
+		t.Errorf("repeat clicks = %d; want 2", stats.RepeatClicks)
+	}
+	if len(stats.AcceptsPerDay) != 2 || stats.AcceptsPerDay[1].Accepts != 2 {
+		t.Errorf("accepts per day = %+v; want 2 today", stats.AcceptsPerDay)
+	}
+}
@@ -0,0 +1,231 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ログの出力元を表す属性
+const KeyComponent = "component"
+
+const (
+	ComponentApp      = "app"
+	ComponentDetector = "detector"
+	ComponentSystem   = "system"
+	ComponentServer   = "server"
+)
+
+var Components = []string{ComponentApp, ComponentDetector, ComponentSystem, ComponentServer}
+
+// ログの出力先
+type Options struct {
+	Level    slog.Level
+	File     string // 空の場合はファイルに出力しない
+	MaxBytes int64  // この大きさを超えたらローテーションする
+	MaxFiles int    // 残す古いファイルの数
+	Stdout   io.Writer
+}
+
+// WebSocketなどに送る1件分のログ
+type Entry struct {
+	Time      time.Time
+	Level     slog.Level
+	Component string
+	Message   string
+	Attrs     map[string]string
+}
+
+// ログを受け取る関数（Web UI への送信用）
+var feed atomic.Pointer[func(Entry)]
+
+// 標準出力・ファイル・フィードに出力するロガーを既定のロガーにする
+// 戻り値はファイルを閉じるためのもの
+func Setup(opts Options) (io.Closer, error) {
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	handlers := []slog.Handler{
+		slog.NewTextHandler(stdout, handlerOpts),
+		&feedHandler{level: opts.Level},
+	}
+
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := NewRotatingFile(opts.File, opts.MaxBytes, opts.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, slog.NewJSONHandler(file, handlerOpts))
+		closer = file
+	}
+
+	slog.SetDefault(slog.New(fanout(handlers)))
+	return closer, nil
+}
+
+// コンポーネント名付きのロガー（Setup の後に呼ぶ）
+func For(component string) *slog.Logger {
+	return slog.Default().With(KeyComponent, component)
+}
+
+// フィードの送り先を設定する（nil で解除）
+func SetFeed(fn func(Entry)) {
+	if fn == nil {
+		feed.Store(nil)
+		return
+	}
+	feed.Store(&fn)
+}
+
+// "debug" / "info" / "warn" / "error" を変換する
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("ログレベルが不正です: %q", s)
+	}
+	return level, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// 複数の出力先に同じレコードを渡す
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// レコードを Entry に変換してフィードに渡す
+type feedHandler struct {
+	level     slog.Level
+	component string
+	attrs     map[string]string // WithAttrs で追加された属性
+	group     string
+}
+
+func (h *feedHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level && feed.Load() != nil
+}
+
+func (h *feedHandler) Handle(_ context.Context, r slog.Record) error {
+	fn := feed.Load()
+	if fn == nil {
+		return nil
+	}
+
+	entry := Entry{
+		Time:      r.Time,
+		Level:     r.Level,
+		Component: h.component,
+		Message:   r.Message,
+		Attrs:     copyAttrs(h.attrs),
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == KeyComponent && h.group == "" {
+			entry.Component = a.Value.String()
+			return true
+		}
+		addAttr(entry.Attrs, h.group, a)
+		return true
+	})
+	(*fn)(entry)
+	return nil
+}
+
+func (h *feedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = copyAttrs(h.attrs)
+	for _, a := range attrs {
+		if a.Key == KeyComponent && h.group == "" {
+			next.component = a.Value.String()
+			continue
+		}
+		addAttr(next.attrs, h.group, a)
+	}
+	return &next
+}
+
+func (h *feedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = joinKey(h.group, name)
+	return &next
+}
+
+func addAttr(dst map[string]string, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, child := range a.Value.Group() {
+			addAttr(dst, joinKey(group, a.Key), child)
+		}
+		return
+	}
+	dst[joinKey(group, a.Key)] = a.Value.String()
+}
+
+func copyAttrs(attrs map[string]string) map[string]string {
+	result := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		result[k] = v
+	}
+	return result
+}
+
+func joinKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	if key == "" {
+		return group
+	}
+	return strings.Join([]string{group, key}, ".")
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) = %v", line, err)
+		}
+	}
+	if got := readFile(t, path); got != "third\n" {
+		t.Errorf("%s = %q; want %q", path, got, "third\n")
+	}
+	if got := readFile(t, path+".1"); got != "second\n" {
+		t.Errorf("%s.1 = %q; want %q", path, got, "second\n")
+	}
+	if got := readFile(t, path+".2"); got != "first\n" {
+		t.Errorf("%s.2 = %q; want %q", path, got, "first\n")
+	}
+}
+
+// 移動先が使えずローテーションに失敗しても、元のファイルに書き込みを続ける
+func TestRotatingFileRenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// .1 を空でないディレクトリにして移動できないようにする
+	if err := os.MkdirAll(filepath.Join(path+".1", "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) = %v", line, err)
+		}
+	}
+	if got, want := readFile(t, path), "first\nsecond\nthird\n"; got != want {
+		t.Errorf("%s = %q; want %q", path, got, want)
+	}
+
+	// 移動できるようになれば次の書き込みでローテーションする
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("fourth\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "fourth\n" {
+		t.Errorf("%s = %q; want %q", path, got, "fourth\n")
+	}
+	if got := readFile(t, path+".1"); !strings.HasSuffix(got, "third\n") {
+		t.Errorf("%s.1 = %q; want the rotated lines", path, got)
+	}
+}
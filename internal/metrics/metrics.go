@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Prometheus のテキスト形式で出力するメトリクスの集まり
+type Registry struct {
+	mutex    sync.Mutex
+	families []*family
+	names    map[string]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// 出力する1つの値
+type sample struct {
+	suffix string // _bucket / _sum / _count など
+	labels []labelPair
+	value  float64
+}
+
+type labelPair struct {
+	name, value string
+}
+
+// 同じ名前のメトリクス（ラベルごとの値をまとめたもの）
+type family struct {
+	name    string
+	help    string
+	kind    string // counter / gauge / histogram
+	collect func() []sample
+}
+
+func (r *Registry) register(name, help, kind string, collect func() []sample) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.names[name] {
+		panic("metrics: 同じ名前のメトリクスが登録されています: " + name)
+	}
+	r.names[name] = true
+	r.families = append(r.families, &family{name: name, help: help, kind: kind, collect: collect})
+}
+
+// 増えるだけの値
+type Counter struct {
+	value atomic.Uint64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(name, help, "counter", func() []sample {
+		return []sample{{value: float64(c.Value())}}
+	})
+	return c
+}
+
+// 他で数えている値をカウンターとして出力する
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, "counter", func() []sample {
+		return []sample{{value: fn()}}
+	})
+}
+
+// 出力時に値を取得するゲージ
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, "gauge", func() []sample {
+		return []sample{{value: fn()}}
+	})
+}
+
+// 取りうる状態のうち現在のものだけを 1 にするゲージ（label に状態名が入る）
+func (r *Registry) NewStateSet(name, help, label string, states []string, current func() string) {
+	r.register(name, help, "gauge", func() []sample {
+		now := current()
+		samples := make([]sample, len(states))
+		for i, state := range states {
+			value := 0.0
+			if state == now {
+				value = 1
+			}
+			samples[i] = sample{labels: []labelPair{{name: label, value: state}}, value: value}
+		}
+		return samples
+	})
+}
+
+// 値の分布（buckets は昇順の上限値）
+type Histogram struct {
+	mutex   sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(value float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *Histogram) samples(labels []labelPair) []sample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	samples := make([]sample, 0, len(h.buckets)+3)
+	for i, bound := range h.buckets {
+		samples = append(samples, sample{suffix: "_bucket", labels: withLabel(labels, "le", formatFloat(bound)), value: float64(h.counts[i])})
+	}
+	samples = append(samples,
+		sample{suffix: "_bucket", labels: withLabel(labels, "le", "+Inf"), value: float64(h.count)},
+		sample{suffix: "_sum", labels: labels, value: h.sum},
+		sample{suffix: "_count", labels: labels, value: float64(h.count)},
+	)
+	return samples
+}
+
+// ラベルごとのヒストグラム
+type HistogramVec struct {
+	vec[*Histogram]
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	v := &HistogramVec{vec[*Histogram]{labels: labels, items: map[string]*item[*Histogram]{}, create: func() *Histogram {
+		return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+	}}}
+	r.register(name, help, "histogram", func() []sample {
+		var samples []sample
+		v.each(func(labels []labelPair, h *Histogram) {
+			samples = append(samples, h.samples(labels)...)
+		})
+		return samples
+	})
+	return v
+}
+
+// ラベルの値ごとに作成したメトリクス
+type vec[T any] struct {
+	mutex  sync.Mutex
+	labels []string
+	items  map[string]*item[T]
+	create func() T
+}
+
+type item[T any] struct {
+	values []string
+	metric T
+}
+
+// ラベルの値（登録時のラベルと同じ順）に対応するメトリクス
+func (v *vec[T]) With(values ...string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: ラベルの数が異なります (%d 個, 想定 %d 個)", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if it, ok := v.items[key]; ok {
+		return it.metric
+	}
+	it := &item[T]{values: append([]string{}, values...), metric: v.create()}
+	v.items[key] = it
+	return it.metric
+}
+
+// ラベルの値の順に列挙する
+func (v *vec[T]) each(fn func(labels []labelPair, metric T)) {
+	v.mutex.Lock()
+	keys := make([]string, 0, len(v.items))
+	for key := range v.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]*item[T], len(keys))
+	for i, key := range keys {
+		items[i] = v.items[key]
+	}
+	v.mutex.Unlock()
+
+	for _, it := range items {
+		labels := make([]labelPair, len(v.labels))
+		for i, name := range v.labels {
+			labels[i] = labelPair{name: name, value: it.values[i]}
+		}
+		fn(labels, it.metric)
+	}
+}
+
+func withLabel(labels []labelPair, name, value string) []labelPair {
+	return append(append([]labelPair{}, labels...), labelPair{name: name, value: value})
+}
+
+// テキスト形式で書き出す（登録順）
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	families := append([]*family{}, r.families...)
+	r.mutex.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.collect() {
+			bw.WriteString(f.name)
+			bw.WriteString(s.suffix)
+			if len(s.labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l.name, escapeLabel(l.value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// /metrics 用のハンドラー
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"lol-auto-accept/internal/app"
+)
+
+// 設定の変更として受け付ける本文の上限
+const maxConfigBytes = 64 << 10
+
+// REST APIで返す監視状態
+type stateResponse struct {
+	Running         bool   `json:"running"`
+	WaitingForMatch bool   `json:"waiting_for_match"`
+	AutoWatching    bool   `json:"auto_watching"`
+	Status          string `json:"status"`
+	DryRun          bool   `json:"dry_run"`
+	PollIntervalMs  int64  `json:"poll_interval_ms"`
+	PollMode        string `json:"poll_mode"`
+	TemplatePack    string `json:"template_pack"`
+}
+
+func (s *Server) currentState() stateResponse {
+	state := s.app.CurrentState()
+	interval, mode := s.app.GetPollRate()
+	return stateResponse{
+		Running:         state.Running,
+		WaitingForMatch: state.WaitingForMatch,
+		AutoWatching:    state.AutoWatching,
+		Status:          state.Status,
+		DryRun:          s.app.IsDryRun(),
+		PollIntervalMs:  interval.Milliseconds(),
+		PollMode:        mode,
+		TemplatePack:    s.app.ActiveTemplatePack(),
+	}
+}
+
+// 現在の監視状態
+func (s *Server) HandleState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.currentState())
+}
+
+// 監視を開始する（WebSocket の start と同じ）
+func (s *Server) HandleMonitoringStart(w http.ResponseWriter, r *http.Request) {
+	if err := s.app.StartMonitoring(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, s.currentState())
+}
+
+// 監視を停止する（WebSocket の stop と同じ）
+func (s *Server) HandleMonitoringStop(w http.ResponseWriter, r *http.Request) {
+	s.app.StopMonitoring()
+	writeJSON(w, s.currentState())
+}
+
+// マッチング画面を検出したら監視を開始する自動監視を有効にする
+func (s *Server) HandleAutoWatchStart(w http.ResponseWriter, r *http.Request) {
+	if err := s.app.StartAutoWatcher(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, s.currentState())
+}
+
+func (s *Server) HandleAutoWatchStop(w http.ResponseWriter, r *http.Request) {
+	s.app.StopAutoWatcher()
+	writeJSON(w, s.currentState())
+}
+
+// 環境テストを実行して結果を返す（WebSocket の test と同じ、完了まで時間がかかる）
+func (s *Server) HandleEnvironmentTest(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.app.TestEnvironment())
+}
+
+// 設定ファイルの内容
+func (s *Server) HandleConfig(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.app.Config()
+	if err != nil {
+		configError(w, err)
+		return
+	}
+	writeJSON(w, cfg)
+}
+
+// 設定の一部を変更する（本文は変更する項目だけを含む設定の JSON）
+func (s *Server) HandleConfigUpdate(w http.ResponseWriter, r *http.Request) {
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg, restart, err := s.app.UpdateConfig(patch)
+	if err != nil {
+		configError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"config":           cfg,
+		"restart_required": restart,
+	})
+}
+
+// 設定操作のエラーをステータスコードに対応づける（テンプレートパックの切り替えはテンプレート操作と同じ）
+func configError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, app.ErrInvalidConfig):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, app.ErrNoConfigPath):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		templateError(w, err)
+	}
+}
+
+// OpenAPI仕様
+func (s *Server) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(openAPISpec))
+}
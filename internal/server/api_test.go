@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lol-auto-accept/internal/app"
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/system"
+)
+
+const testToken = "test-token-0123456789abcdef"
+
+// キャプチャを使わないテスト用のキャプチャ元
+type noSource struct{}
+
+func (noSource) Capture(capture.Region) (*capture.Frame, error) {
+	return nil, errors.New("no display")
+}
+
+// 設定ファイルを持つアプリと、そのルーティング
+func newTestHandler(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Server.Token = testToken
+	cfg.Templates.Dir = filepath.Join(dir, "templates")
+	cfg.History.File = ""
+	path := filepath.Join(dir, "config.json")
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	a := app.NewAppWith(cfg, noSource{}, system.NewDryRunController(), clock.Real)
+	a.SetConfigPath(path)
+	return NewServer(a, cfg.Server).SetupRoutes(), path
+}
+
+func request(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestConfigDoesNotExposeToken(t *testing.T) {
+	h, path := newTestHandler(t)
+
+	rec := request(t, h, http.MethodGet, "/api/config", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/config status = %d; want %d (%s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), testToken) {
+		t.Errorf("GET /api/config = %s; want no token", rec.Body)
+	}
+
+	rec = request(t, h, http.MethodPatch, "/api/config", `{"mouse": {"humanize": true}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH /api/config status = %d; want %d (%s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), testToken) {
+		t.Errorf("PATCH /api/config = %s; want no token", rec.Body)
+	}
+
+	// 保存された設定のトークンは消さない
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Token != testToken || !cfg.Mouse.Humanize {
+		t.Errorf("saved config = %+v; want the token kept and humanize on", cfg)
+	}
+}
+
+func TestConfigUpdateRejectsServerCredentials(t *testing.T) {
+	h, path := newTestHandler(t)
+
+	for _, patch := range []string{
+		`{"server": {"token": "attacker"}}`,
+		`{"server": {"token": ""}}`,
+		`{"server": {"address": "0.0.0.0:8081"}}`,
+		`{"mouse": {"humanize": true}, "server": {"address": "127.0.0.1:9000"}}`,
+	} {
+		rec := request(t, h, http.MethodPatch, "/api/config", patch)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PATCH %s status = %d; want %d", patch, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Token != testToken || cfg.Server.Address != config.Default().Server.Address || cfg.Mouse.Humanize {
+		t.Errorf("saved config = %+v; want unchanged", cfg)
+	}
+}
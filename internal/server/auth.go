@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"lol-auto-accept/internal/websocket"
+)
+
+// ブラウザに保存するトークンの Cookie 名
+const authCookie = "lol_auto_accept_token"
+
+// トークンなしで利用できるパス（ウォッチドッグなどからの死活監視用）
+var publicPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// 他のサイトからのリクエストを拒否し、トークンを確認する
+// ブラウザは ?token= 付きのURLを開くと Cookie にトークンを保存し、以降は Cookie で認証する
+// スクリプトなどからは Authorization: Bearer <token> ヘッダーで認証する
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.SameOrigin(r) {
+			http.Error(w, "他のサイトからのリクエストは受け付けません", http.StatusForbidden)
+			return
+		}
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token := r.URL.Query().Get("token"); token != "" && r.URL.Path == "/" && r.Method == http.MethodGet {
+			if !s.validToken(token) {
+				http.Error(w, "トークンが正しくありません", http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     authCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+			// トークンがブラウザの履歴に残らないようにする
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		}
+
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "認証が必要です（起動時に表示されたURLを開くか、Authorization ヘッダーにトークンを指定してください）", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authorization ヘッダーまたは Cookie のトークンを確認
+func (s *Server) authorized(r *http.Request) bool {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		return ok && s.validToken(token)
+	}
+	if cookie, err := r.Cookie(authCookie); err == nil {
+		return s.validToken(cookie.Value)
+	}
+	return false
+}
+
+func (s *Server) validToken(token string) bool {
+	if s.token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+)
+
+// ブラウザで選択した範囲（クライアント座標）
+type calibrationBox struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+func (b calibrationBox) rect() image.Rectangle {
+	return image.Rect(b.X, b.Y, b.X+b.W, b.Y+b.H)
+}
+
+type calibrationRequest struct {
+	Name     string         `json:"name"`
+	Accept   calibrationBox `json:"accept"`
+	Matching calibrationBox `json:"matching"`
+}
+
+// 現在の画面をキャリブレーション用にキャプチャ
+func (s *Server) HandleCalibrationCapture(w http.ResponseWriter, r *http.Request) {
+	frame, err := s.app.CaptureCalibrationFrame()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer frame.Release()
+
+	writeJSON(w, map[string]interface{}{
+		"width":  frame.Client.Dx(),
+		"height": frame.Client.Dy(),
+	})
+}
+
+// キャプチャした画面のPNG
+func (s *Server) HandleCalibrationFrame(w http.ResponseWriter, r *http.Request) {
+	frame := s.app.AcquireCalibrationFrame()
+	if frame == nil {
+		http.Error(w, "キャリブレーション用の画面がキャプチャされていません", http.StatusNotFound)
+		return
+	}
+	defer frame.Release()
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	png.Encode(w, frame.Image)
+}
+
+// 選択範囲をテンプレートパックとして保存し、検出テストの結果を返す
+func (s *Server) HandleCalibrationSave(w http.ResponseWriter, r *http.Request) {
+	var req calibrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.app.SaveCalibration(req.Name, req.Accept.rect(), req.Matching.rect())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, result)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"lol-auto-accept/internal/app"
+)
+
+// 生存確認（監視ループが止まっている場合は 503）
+func (s *Server) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, s.app.Health())
+}
+
+// 準備状況の確認（キャプチャ・テンプレート・マウス操作のいずれかが使えない場合は 503）
+func (s *Server) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, s.app.Ready())
+}
+
+func writeHealth(w http.ResponseWriter, report app.HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == app.CheckFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
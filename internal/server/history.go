@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 履歴・集計の既定値
+const (
+	defaultHistoryLimit = 50
+	defaultStatsDays    = 14
+	maxStatsDays        = 365
+)
+
+// レディチェックの履歴（新しい順、?limit= で件数を指定）
+func (s *Server) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	limit := queryInt(r, "limit", defaultHistoryLimit)
+	if limit < 0 {
+		http.Error(w, "limit は0以上で指定してください", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{"enabled": false}
+	if store := s.app.GetHistory(); store != nil {
+		response["enabled"] = true
+		response["records"] = store.Records(limit)
+	}
+	writeJSON(w, response)
+}
+
+// 承認数・反応時間・見逃し率などの集計（?days= で日別の承認数の日数を指定）
+func (s *Server) HandleHistoryStats(w http.ResponseWriter, r *http.Request) {
+	days := queryInt(r, "days", defaultStatsDays)
+	if days < 1 || days > maxStatsDays {
+		http.Error(w, "days は1〜365で指定してください", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{"enabled": false}
+	if store := s.app.GetHistory(); store != nil {
+		response["enabled"] = true
+		response["stats"] = store.Stats(time.Now(), days)
+	}
+	writeJSON(w, response)
+}
+
+// クエリパラメータの整数（省略時は def、不正な値は -1）
+func queryInt(r *http.Request, key string, def int) int {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,312 @@
+package server
+
+// /api/openapi.json で配布する REST API の仕様（OpenAPI 3.0）
+// テンプレートパックとキャリブレーションの操作は Web UI 向けのため含めていない
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "LoL Auto Accept",
+    "version": "1.0.0",
+    "description": "監視の開始・停止、環境テスト、設定の取得・変更を行うAPI。WebSocket (/ws) の操作と同じ処理を行います。/healthz と /readyz 以外は起動時に表示されるトークンが必要です。"
+  },
+  "security": [{"bearerAuth": []}],
+  "paths": {
+    "/api/state": {
+      "get": {
+        "summary": "現在の監視状態",
+        "responses": {
+          "200": {"$ref": "#/components/responses/State"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/monitoring/start": {
+      "post": {
+        "summary": "監視を開始する",
+        "description": "監視中の場合は何もしません。",
+        "responses": {
+          "200": {"$ref": "#/components/responses/State"},
+          "500": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/monitoring/stop": {
+      "post": {
+        "summary": "監視を停止する",
+        "responses": {
+          "200": {"$ref": "#/components/responses/State"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/autowatch/start": {
+      "post": {
+        "summary": "自動監視を開始する",
+        "description": "マッチング画面を検出したら自動で監視を開始します。",
+        "responses": {
+          "200": {"$ref": "#/components/responses/State"},
+          "500": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/autowatch/stop": {
+      "post": {
+        "summary": "自動監視を停止する",
+        "description": "実行中の監視は停止しません。",
+        "responses": {
+          "200": {"$ref": "#/components/responses/State"},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/environment/test": {
+      "post": {
+        "summary": "環境テストを実行する",
+        "description": "スクリーンショットの取得・テンプレートの読み込み・検出速度・マウス操作を確認します。完了まで数秒かかります。",
+        "responses": {
+          "200": {
+            "description": "テスト結果",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/EnvironmentReport"}}}
+          },
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/config": {
+      "get": {
+        "summary": "設定ファイルの内容",
+        "description": "起動時のフラグ (-dry-run など) による上書きは含みません。server.token は空で返します。",
+        "responses": {
+          "200": {
+            "description": "設定",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Config"}}}
+          },
+          "401": {"$ref": "#/components/responses/Unauthorized"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "patch": {
+        "summary": "設定の一部を変更する",
+        "description": "本文には変更する項目だけを含めます。mouse と templates.active はすぐに反映し、それ以外は再起動後に反映します。server.token と server.address は変更できません（400）。",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Config"}}}
+        },
+        "responses": {
+          "200": {
+            "description": "変更後の設定",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "config": {"$ref": "#/components/schemas/Config"},
+                    "restart_required": {"type": "boolean"}
+                  }
+                }
+              }
+            }
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Unauthorized"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/history": {
+      "get": {
+        "summary": "レディチェックの履歴（新しい順）",
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 0, "default": 50}}],
+        "responses": {
+          "200": {"description": "履歴", "content": {"application/json": {"schema": {"type": "object"}}}},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/api/history/stats": {
+      "get": {
+        "summary": "レディチェックの集計",
+        "parameters": [{"name": "days", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 365, "default": 14}}],
+        "responses": {
+          "200": {"description": "集計", "content": {"application/json": {"schema": {"type": "object"}}}},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    },
+    "/healthz": {
+      "get": {
+        "summary": "生存確認",
+        "security": [],
+        "responses": {
+          "200": {"$ref": "#/components/responses/Health"},
+          "503": {"$ref": "#/components/responses/Health"}
+        }
+      }
+    },
+    "/readyz": {
+      "get": {
+        "summary": "準備状況の確認",
+        "security": [],
+        "responses": {
+          "200": {"$ref": "#/components/responses/Health"},
+          "503": {"$ref": "#/components/responses/Health"}
+        }
+      }
+    },
+    "/metrics": {
+      "get": {
+        "summary": "Prometheus形式のメトリクス",
+        "responses": {
+          "200": {"description": "メトリクス", "content": {"text/plain": {"schema": {"type": "string"}}}},
+          "401": {"$ref": "#/components/responses/Unauthorized"}
+        }
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "State": {
+        "description": "監視状態",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/State"}}}
+      },
+      "Health": {
+        "description": "確認結果（status が fail の場合は 503）",
+        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HealthReport"}}}
+      },
+      "Error": {
+        "description": "エラー",
+        "content": {"text/plain": {"schema": {"type": "string"}}}
+      },
+      "Unauthorized": {
+        "description": "トークンがない、または正しくない",
+        "headers": {"WWW-Authenticate": {"schema": {"type": "string"}, "example": "Bearer"}},
+        "content": {"text/plain": {"schema": {"type": "string"}}}
+      }
+    },
+    "securitySchemes": {
+      "bearerAuth": {
+        "type": "http",
+        "scheme": "bearer",
+        "description": "起動時に表示されるトークン。ブラウザでは Cookie (lol_auto_accept_token) でも認証できます。"
+      }
+    },
+    "schemas": {
+      "State": {
+        "type": "object",
+        "properties": {
+          "running": {"type": "boolean"},
+          "waiting_for_match": {"type": "boolean"},
+          "auto_watching": {"type": "boolean"},
+          "status": {"type": "string"},
+          "dry_run": {"type": "boolean"},
+          "poll_interval_ms": {"type": "integer"},
+          "poll_mode": {"type": "string", "enum": ["idle", "lobby", "matching", "backoff"]},
+          "template_pack": {"type": "string"}
+        }
+      },
+      "CheckStatus": {"type": "string", "enum": ["ok", "warn", "fail", "skipped"]},
+      "HealthCheck": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string", "enum": ["capture", "templates", "input", "client", "window", "tick"]},
+          "status": {"$ref": "#/components/schemas/CheckStatus"},
+          "detail": {"type": "string"}
+        }
+      },
+      "HealthReport": {
+        "type": "object",
+        "properties": {
+          "status": {"$ref": "#/components/schemas/CheckStatus"},
+          "running": {"type": "boolean"},
+          "auto_watching": {"type": "boolean"},
+          "last_tick": {"type": "string", "format": "date-time"},
+          "checks": {"type": "array", "items": {"$ref": "#/components/schemas/HealthCheck"}}
+        }
+      },
+      "DetectionTest": {
+        "type": "object",
+        "properties": {
+          "detected": {"type": "boolean"},
+          "elapsed_ms": {"type": "integer"},
+          "score": {"type": "number"},
+          "x": {"type": "integer"},
+          "y": {"type": "integer"}
+        }
+      },
+      "EnvironmentReport": {
+        "type": "object",
+        "properties": {
+          "status": {"$ref": "#/components/schemas/CheckStatus"},
+          "os": {"type": "string"},
+          "dry_run": {"type": "boolean"},
+          "checks": {"type": "array", "items": {"$ref": "#/components/schemas/HealthCheck"}},
+          "matching": {"$ref": "#/components/schemas/DetectionTest"},
+          "accept": {"$ref": "#/components/schemas/DetectionTest"},
+          "stats": {
+            "type": "object",
+            "properties": {
+              "frames_processed": {"type": "integer"},
+              "frames_skipped": {"type": "integer"}
+            }
+          },
+          "elapsed_ms": {"type": "integer"}
+        }
+      },
+      "Config": {
+        "type": "object",
+        "properties": {
+          "dry_run": {"type": "boolean"},
+          "capture": {
+            "type": "object",
+            "properties": {"window_title": {"type": "string"}}
+          },
+          "mouse": {
+            "type": "object",
+            "properties": {
+              "humanize": {"type": "boolean"},
+              "duration_ms": {"type": "integer", "minimum": 0},
+              "easing": {"type": "string", "enum": ["linear", "ease-in", "ease-out", "ease-in-out"]},
+              "jitter": {"type": "number", "minimum": 0, "maximum": 1}
+            }
+          },
+          "debug": {
+            "type": "object",
+            "properties": {
+              "snapshots": {"type": "boolean"},
+              "dir": {"type": "string"},
+              "max_snapshots": {"type": "integer", "minimum": 0}
+            }
+          },
+          "templates": {
+            "type": "object",
+            "properties": {
+              "dir": {"type": "string"},
+              "active": {"type": "string"}
+            }
+          },
+          "log": {
+            "type": "object",
+            "properties": {
+              "level": {"type": "string", "enum": ["debug", "info", "warn", "error"]},
+              "file": {"type": "string"},
+              "max_size_mb": {"type": "integer", "minimum": 0},
+              "max_files": {"type": "integer", "minimum": 0}
+            }
+          },
+          "history": {
+            "type": "object",
+            "properties": {"file": {"type": "string"}}
+          },
+          "client": {
+            "type": "object",
+            "properties": {"lockfile": {"type": "string"}}
+          }
+        }
+      }
+    }
+  }
+}
+`
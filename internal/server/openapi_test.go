@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPISpecSecurity(t *testing.T) {
+	var spec struct {
+		Security []map[string][]string `json:"security"`
+		Paths    map[string]map[string]struct {
+			Security  *[]map[string][]string     `json:"security"`
+			Responses map[string]json.RawMessage `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Responses       map[string]json.RawMessage `json:"responses"`
+			SecuritySchemes map[string]struct {
+				Type   string `json:"type"`
+				Scheme string `json:"scheme"`
+			} `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(openAPISpec), &spec); err != nil {
+		t.Fatalf("openAPISpec is not valid JSON: %v", err)
+	}
+
+	scheme, ok := spec.Components.SecuritySchemes["bearerAuth"]
+	if !ok || scheme.Type != "http" || scheme.Scheme != "bearer" {
+		t.Errorf("securitySchemes.bearerAuth = %+v; want http bearer", scheme)
+	}
+	if len(spec.Security) != 1 {
+		t.Fatalf("security = %v; want [bearerAuth]", spec.Security)
+	}
+	if _, ok := spec.Security[0]["bearerAuth"]; !ok {
+		t.Errorf("security = %v; want [bearerAuth]", spec.Security)
+	}
+	if _, ok := spec.Components.Responses["Unauthorized"]; !ok {
+		t.Error("components.responses.Unauthorized is missing")
+	}
+
+	for path, ops := range spec.Paths {
+		for method, op := range ops {
+			_, has401 := op.Responses["401"]
+			if publicPaths[path] {
+				if op.Security == nil || len(*op.Security) != 0 {
+					t.Errorf("%s %s: security = %v; want []", method, path, op.Security)
+				}
+				if has401 {
+					t.Errorf("%s %s documents 401 but does not require a token", method, path)
+				}
+				continue
+			}
+			if !has401 {
+				t.Errorf("%s %s: 401 response is not documented", method, path)
+			}
+		}
+	}
+}
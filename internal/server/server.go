@@ -1,27 +1,63 @@
 package server
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	gorillaws "github.com/gorilla/websocket"
 	"lol-auto-accept/internal/app"
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/websocket"
 )
 
 type Server struct {
-	app *app.App
+	app      *app.App
+	address  string
+	token    string
+	certFile string
+	keyFile  string
+
+	// LoadCertificate を呼んだ場合のみ設定される
+	tlsConfig   *tls.Config
+	fingerprint string
 }
 
-func NewServer(app *app.App) *Server {
+func NewServer(app *app.App, cfg config.ServerConfig) *Server {
 	return &Server{
-		app: app,
+		app:      app,
+		address:  cfg.Address,
+		token:    cfg.Token,
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
 	}
 }
 
+// ブラウザで開くURL（トークン付き、ループバックやすべてのインターフェースで待ち受ける場合は localhost）
+func (s *Server) URL() string {
+	host, port, err := net.SplitHostPort(s.address)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && (ip.IsUnspecified() || ip.IsLoopback())) {
+		host = "localhost"
+	}
+	scheme := "http"
+	if s.tlsConfig != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + "/?token=" + s.token
+}
+
 func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := s.app.GetWebSocketManager().HandleConnection(w, r)
 	if err != nil {
@@ -29,38 +65,81 @@ func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// 接続時に現在の状態を送信
-	if s.app.IsRunning() {
-		s.app.GetWebSocketManager().UpdateStatus("監視中...")
-	} else if s.app.IsAutoWatching() {
-		s.app.GetWebSocketManager().UpdateStatus("自動監視中...")
-	} else {
-		s.app.GetWebSocketManager().UpdateStatus("停止中")
-	}
-
+	manager := s.app.GetWebSocketManager()
 	defer func() {
-		s.app.GetWebSocketManager().RemoveConnection(conn)
+		manager.RemoveConnection(conn)
 	}()
 
+	// 接続時に、このクライアントにだけ現在の状態を送信
+	state := s.app.CurrentState()
+	manager.Send(conn, websocket.NewHello(manager.LastSeq()))
+	manager.Send(conn, state)
+	manager.Send(conn, websocket.NewStatusUpdate(state.Status))
+	manager.Send(conn, websocket.NewModeUpdate(s.app.IsDryRun()))
+	interval, mode := s.app.GetPollRate()
+	manager.Send(conn, websocket.NewRateUpdate(interval.Milliseconds(), mode))
+
+	// 直近の履歴を再送（再接続時は since 以降のみ）
+	since, _ := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+	manager.Replay(conn, since)
+
 	for {
-		var msg map[string]interface{}
-		err := conn.ReadJSON(&msg)
+		_, data, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
 
-		action := msg["action"].(string)
-		switch action {
-		case "start":
-			s.app.StartMonitoring()
-		case "stop":
-			s.app.StopMonitoring()
-		case "test":
-			s.app.TestEnvironment()
+		cmd, perr := websocket.ParseCommand(data)
+		if perr != nil {
+			manager.Send(conn, websocket.NewError(perr))
+			continue
 		}
+		// 環境テストは完了まで時間がかかるため、他の操作を受け付けられるよう別のゴルーチンで実行する
+		if cmd.Action == websocket.ActionTest {
+			go s.runCommand(conn, cmd)
+			continue
+		}
+		s.runCommand(conn, cmd)
 	}
 }
 
+// 操作を実行し、完了したら ack、失敗したら error を返す
+func (s *Server) runCommand(conn *gorillaws.Conn, cmd websocket.Command) {
+	var err error
+	switch cmd.Action {
+	case websocket.ActionStart:
+		err = s.app.StartMonitoring()
+	case websocket.ActionStop:
+		s.app.StopMonitoring()
+	case websocket.ActionTest:
+		s.app.TestEnvironment()
+	}
+
+	manager := s.app.GetWebSocketManager()
+	if err != nil {
+		manager.Send(conn, websocket.NewError(websocket.CommandFailed(cmd, err)))
+		return
+	}
+	manager.Send(conn, websocket.NewAck(cmd))
+}
+
+// デバッグスナップショットの一覧（新しい順）
+func (s *Server) HandleSnapshotList(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{"enabled": false}
+	if writer := s.app.GetSnapshotWriter(); writer != nil {
+		snaps, err := writer.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response["enabled"] = true
+		response["snapshots"] = snaps
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func (s *Server) ServeHTML(w http.ResponseWriter, r *http.Request) {
 	htmlContent := `
 <!DOCTYPE html>
@@ -85,7 +164,25 @@ func (s *Server) ServeHTML(w http.ResponseWriter, r *http.Request) {
         .log { height: 300px; overflow-y: auto; border: 1px solid #ddd; padding: 10px; background-color: #fafafa; font-family: monospace; font-size: 12px; }
         .log-entry { margin: 2px 0; padding: 2px 0; }
         .timestamp { color: #666; }
+        .log-level { display: inline-block; width: 3.5em; font-weight: bold; }
+        .log-debug .log-level { color: #999; }
+        .log-info .log-level { color: #36c; }
+        .log-warn .log-level { color: #c80; }
+        .log-error .log-level { color: #c33; }
+        .log-component { color: #666; }
+        .log-attrs { color: #888; }
+        .log-filter { margin-bottom: 5px; font-size: 13px; }
         .performance { background-color: #e3f2fd; padding: 10px; margin: 10px 0; border-radius: 4px; font-size: 12px; }
+        .dryrun { display: none; background-color: #fff3e0; color: #e65100; padding: 10px; margin: 10px 0; border-radius: 4px; font-weight: bold; text-align: center; }
+        .snapshots { max-height: 200px; overflow-y: auto; border: 1px solid #ddd; padding: 10px; background-color: #fafafa; font-family: monospace; font-size: 12px; }
+        .clicks { max-height: 120px; overflow-y: auto; border: 1px solid #ddd; padding: 10px; background-color: #fafafa; font-family: monospace; font-size: 12px; }
+        .calibration { border: 1px solid #ddd; padding: 10px; background-color: #fafafa; font-size: 12px; }
+        .calibration canvas { display: none; width: 100%; margin: 10px 0; cursor: crosshair; border: 1px solid #ccc; }
+        .calibration input[type=text] { padding: 6px; width: 160px; }
+        .packs { border: 1px solid #ddd; padding: 10px; background-color: #fafafa; font-size: 12px; }
+        .pack { border-bottom: 1px solid #eee; padding: 6px 0; }
+        .pack img { max-height: 40px; margin-right: 8px; vertical-align: middle; border: 1px solid #ccc; }
+        .pack.active { background-color: #efe; }
     </style>
 </head>
 <body>
@@ -95,13 +192,69 @@ func (s *Server) ServeHTML(w http.ResponseWriter, r *http.Request) {
             <strong>完全自動:</strong> アプリ起動と同時に「対戦を検出中」画面を監視開始 → 自動で承認ボタンクリック → 5秒後に画面が変わったら監視停止
         </div>
         <div id="status" class="status stopped">ステータス: 停止中</div>
+        <div id="rate" class="performance">ポーリング間隔: -</div>
+        <div id="stats" class="performance">フレーム統計: -</div>
+        <div class="performance">接続: {{CONNECTION}}</div>
+        <div id="dryrun" class="dryrun">ドライランモード: マウスは操作されず、クリック予定位置のみ記録されます</div>
         <div class="buttons">
             <button class="start" onclick="sendAction('start')">監視開始</button>
             <button class="stop" onclick="sendAction('stop')">監視停止</button>
             <button class="test" onclick="sendAction('test')">パフォーマンステスト</button>
             <button class="clear" onclick="clearLog()">ログクリア</button>
         </div>
+        <h3>クリック履歴:</h3>
+        <div id="clicks" class="clicks"></div>
+        <h3>キャリブレーション:</h3>
+        <div class="calibration">
+            <div>レディチェック画面を表示した状態でキャプチャし、承認ボタンとマッチング表示をそれぞれドラッグで囲んでください。</div>
+            <div style="margin-top: 8px;">
+                <button class="test" onclick="captureCalibration(5)">5秒後にキャプチャ</button>
+                <button class="test" onclick="captureCalibration(0)">今すぐキャプチャ</button>
+                <span id="calibration-status"></span>
+            </div>
+            <canvas id="calibration-canvas"></canvas>
+            <div>
+                囲む対象:
+                <label><input type="radio" name="calibration-target" value="accept" checked> 承認ボタン (赤)</label>
+                <label><input type="radio" name="calibration-target" value="matching"> マッチング表示 (青)</label>
+            </div>
+            <div style="margin-top: 8px;">
+                パック名: <input type="text" id="calibration-name" value="custom">
+                <button class="start" onclick="saveCalibration()">保存して検出テスト</button>
+            </div>
+            <div id="calibration-result" style="margin-top: 8px;"></div>
+        </div>
+        <h3>テンプレートパック: <button class="test" onclick="loadPacks()">更新</button></h3>
+        <div class="packs">
+            <div id="packs">「更新」で一覧を表示します</div>
+            <form id="pack-upload" style="margin-top: 8px;" onsubmit="uploadPack(event)">
+                パック名: <input type="text" name="name" required>
+                承認ボタン: <input type="file" name="accept" accept="image/png" required>
+                マッチング表示: <input type="file" name="matching" accept="image/png" required>
+                <button class="start" type="submit">アップロード</button>
+            </form>
+            <div id="pack-status"></div>
+        </div>
+        <h3>承認履歴: <button class="test" onclick="loadHistory()">更新</button></h3>
+        <div id="history-stats" class="performance"></div>
+        <div id="history" class="snapshots">「更新」で一覧を表示します</div>
+        <h3>デバッグスナップショット: <button class="test" onclick="loadSnapshots()">更新</button></h3>
+        <div id="snapshots" class="snapshots">「更新」で一覧を表示します</div>
         <h3>ログ:</h3>
+        <div class="log-filter">
+            レベル:
+            <select id="log-level" onchange="applyLogFilter()">
+                <option value="debug">debug 以上</option>
+                <option value="info" selected>info 以上</option>
+                <option value="warn">warn 以上</option>
+                <option value="error">error のみ</option>
+            </select>
+            コンポーネント:
+            <label><input type="checkbox" class="log-component-filter" value="app" checked onchange="applyLogFilter()">app</label>
+            <label><input type="checkbox" class="log-component-filter" value="detector" checked onchange="applyLogFilter()">detector</label>
+            <label><input type="checkbox" class="log-component-filter" value="system" checked onchange="applyLogFilter()">system</label>
+            <label><input type="checkbox" class="log-component-filter" value="server" checked onchange="applyLogFilter()">server</label>
+        </div>
         <div id="log" class="log">
             <div class="log-entry">LoL Auto Accept へようこそ (完全自動版)<br>
             アプリ起動と同時に「対戦を検出中」画面の監視を開始しました</div>
@@ -109,34 +262,348 @@ func (s *Server) ServeHTML(w http.ResponseWriter, r *http.Request) {
     </div>
     
     <script>
-        const ws = new WebSocket('ws://localhost:8081/ws');
+        const PROTOCOL_VERSION = 1;
+        let nextCommandId = 1;
+        const pendingCommands = {};
+        // 受信した履歴イベントの最後の通し番号（再接続時に続きから受け取る）
+        let lastSeq = 0;
+        let ws = connect();
+        
+        function connect() {
+            // ページを開いたアドレスに接続する（HTTPS の場合は wss）
+            const scheme = location.protocol === 'https:' ? 'wss://' : 'ws://';
+            const socket = new WebSocket(scheme + location.host + '/ws' + (lastSeq ? '?since=' + lastSeq : ''));
+            socket.onmessage = handleMessage;
+            socket.onclose = function() {
+                setTimeout(function() { ws = connect(); }, 2000);
+            };
+            return socket;
+        }
         
-        ws.onmessage = function(event) {
+        function handleMessage(event) {
             const data = JSON.parse(event.data);
-            if (data.type === 'log') {
+            if (data.seq) {
+                if (data.seq <= lastSeq) {
+                    return;
+                }
+                lastSeq = data.seq;
+            }
+            if (data.type === 'hello') {
+                if (data.last_seq < lastSeq) {
+                    // サーバーが再起動した
+                    lastSeq = 0;
+                }
+                if (data.protocol !== PROTOCOL_VERSION) {
+                    addLog({timestamp: now(), message: 'サーバーのプロトコルのバージョンが異なります (サーバー: ' + data.protocol + ')'});
+                }
+            } else if (data.type === 'ack') {
+                delete pendingCommands[data.id];
+            } else if (data.type === 'error') {
+                const action = pendingCommands[data.id] || '不明';
+                delete pendingCommands[data.id];
+                addLog({timestamp: now(), message: '操作エラー (' + action + ', ' + data.code + '): ' + data.message});
+            } else if (data.type === 'state') {
+                updateStatus(data);
+                const status = document.getElementById('status');
+                status.className = 'status ' + (data.running ? 'running' : 'stopped');
+            } else if (data.type === 'log') {
                 addLog(data);
             } else if (data.type === 'status') {
                 updateStatus(data);
+            } else if (data.type === 'rate') {
+                const modes = {idle: 'クライアント未検出', lobby: 'ロビー', matching: 'マッチング中', backoff: 'キャプチャエラーのため待機'};
+                document.getElementById('rate').textContent = 'ポーリング間隔: ' + data.interval_ms + 'ms (' + (modes[data.mode] || data.mode) + ')';
+            } else if (data.type === 'stats') {
+                document.getElementById('stats').textContent = 'フレーム統計: 検出処理 ' + data.frames_processed +
+                    ' 回 / 変化なしでスキップ ' + data.frames_skipped + ' 回';
+            } else if (data.type === 'mode') {
+                document.getElementById('dryrun').style.display = data.dry_run ? 'block' : 'none';
+            } else if (data.type === 'click') {
+                addClick(data);
             }
-        };
+        }
+        
+        function addClick(data) {
+            const clicks = document.getElementById('clicks');
+            const entry = document.createElement('div');
+            entry.className = 'log-entry';
+            entry.innerHTML = '<span class="timestamp">[' + data.timestamp + ']</span> ' +
+                (data.dry_run ? '[ドライラン] ' : '') + '(' + data.x + ', ' + data.y + ')';
+            clicks.appendChild(entry);
+            clicks.scrollTop = clicks.scrollHeight;
+        }
+        
+        const LOG_LEVELS = {debug: 0, info: 1, warn: 2, error: 3};
         
         function addLog(data) {
             const log = document.getElementById('log');
             const entry = document.createElement('div');
-            entry.className = 'log-entry';
-            entry.innerHTML = '<span class="timestamp">[' + data.timestamp + ']</span> ' + data.message;
+            const level = data.level || 'info';
+            entry.className = 'log-entry log-' + level;
+            entry.dataset.level = level;
+            entry.dataset.component = data.component || '';
+            let html = '<span class="timestamp">[' + data.timestamp + ']</span> ' +
+                '<span class="log-level">' + level.toUpperCase() + '</span> ';
+            if (data.component) {
+                html += '<span class="log-component">[' + data.component + ']</span> ';
+            }
+            html += escapeHTML(data.message);
+            if (data.attrs) {
+                const attrs = Object.keys(data.attrs).sort().map(function(k) { return k + '=' + data.attrs[k]; });
+                html += ' <span class="log-attrs">' + escapeHTML(attrs.join(' ')) + '</span>';
+            }
+            entry.innerHTML = html;
+            entry.style.display = logVisible(entry) ? '' : 'none';
             log.appendChild(entry);
             log.scrollTop = log.scrollHeight;
         }
         
+        function escapeHTML(text) {
+            const div = document.createElement('div');
+            div.textContent = text;
+            return div.innerHTML;
+        }
+        
+        // 選択中のレベル以上、かつチェックされたコンポーネントのログだけを表示する
+        function logVisible(entry) {
+            if (!entry.dataset.level) {
+                return true;
+            }
+            const minLevel = LOG_LEVELS[document.getElementById('log-level').value];
+            if (LOG_LEVELS[entry.dataset.level] < minLevel) {
+                return false;
+            }
+            if (!entry.dataset.component) {
+                return true;
+            }
+            const checked = Array.from(document.querySelectorAll('.log-component-filter:checked')).map(function(c) { return c.value; });
+            return checked.indexOf(entry.dataset.component) >= 0;
+        }
+        
+        function applyLogFilter() {
+            document.querySelectorAll('#log .log-entry').forEach(function(entry) {
+                entry.style.display = logVisible(entry) ? '' : 'none';
+            });
+        }
+        
         function updateStatus(data) {
             const status = document.getElementById('status');
             status.textContent = 'ステータス: ' + data.status;
-            status.className = 'status ' + (data.status === '監視中...' ? 'running' : 'stopped');
+        }
+        
+        function now() {
+            return new Date().toTimeString().slice(0, 8);
         }
         
         function sendAction(action) {
-            ws.send(JSON.stringify({action: action}));
+            const id = String(nextCommandId++);
+            pendingCommands[id] = action;
+            ws.send(JSON.stringify({v: PROTOCOL_VERSION, id: id, action: action}));
+        }
+        
+        function loadHistory() {
+            const outcomes = {accepted: '承認', declined: '他のプレイヤーが辞退', missed: '見逃し', dodged: 'チャンピオン選択で解散', false_positive: '誤検出'};
+            fetch('/api/history/stats?days=7').then(r => r.json()).then(data => {
+                const stats = document.getElementById('history-stats');
+                if (!data.enabled) {
+                    stats.textContent = '';
+                    return;
+                }
+                const s = data.stats;
+                stats.textContent = '合計 ' + s.total + ' 回 (承認 ' + s.outcomes.accepted + ', 辞退 ' + s.outcomes.declined +
+                    ', 見逃し ' + s.outcomes.missed + ', 解散 ' + s.outcomes.dodged + ', ドライラン ' + s.dry_run + ' 回は除外) / 反応時間の中央値 ' + s.median_reaction_ms +
+                    'ms / 見逃し率 ' + (s.miss_rate * 100).toFixed(1) + '% / 誤検出へのクリック ' + s.false_positive_clicks + ' 回 / 同じレディチェックへの追加クリック ' + s.repeat_clicks + ' 回 / 直近7日: ' +
+                    s.accepts_per_day.map(d => d.date.slice(5) + ' ' + d.accepts).join(', ');
+            });
+            fetch('/api/history?limit=50').then(r => r.json()).then(data => {
+                const list = document.getElementById('history');
+                if (!data.enabled) {
+                    list.textContent = '履歴の保存が無効です (config.json の history.file を設定してください)';
+                    return;
+                }
+                if (data.records.length === 0) {
+                    list.textContent = 'レディチェックの記録はまだありません';
+                    return;
+                }
+                list.innerHTML = '';
+                data.records.forEach(rec => {
+                    const entry = document.createElement('div');
+                    entry.className = 'log-entry';
+                    entry.textContent = new Date(rec.time).toLocaleString() + ' [' + (outcomes[rec.outcome] || rec.outcome) + '] ' +
+                        (rec.dry_run ? '[ドライラン] ' : '') + '検出: ' + rec.method + ' (スコア ' + rec.score.toFixed(3) + ')' +
+                        (rec.clicks > 0 ? ', クリックまで ' + rec.time_to_accept_ms + 'ms, クリック ' + rec.clicks + ' 回' : '');
+                    list.appendChild(entry);
+                });
+            });
+        }
+        
+        function loadSnapshots() {
+            fetch('/debug/snapshots').then(r => r.json()).then(data => {
+                const list = document.getElementById('snapshots');
+                if (!data.enabled) {
+                    list.textContent = 'デバッグモードが無効です (-debug で起動してください)';
+                    return;
+                }
+                if (data.snapshots.length === 0) {
+                    list.textContent = 'スナップショットはまだありません';
+                    return;
+                }
+                list.innerHTML = '';
+                data.snapshots.forEach(snap => {
+                    const entry = document.createElement('div');
+                    entry.className = 'log-entry';
+                    const candidates = snap.trace ? snap.trace.candidates.length : 0;
+                    entry.innerHTML = snap.id + ' [' + snap.decision + '] スコア: ' + snap.score.toFixed(3) +
+                        ', 候補: ' + candidates + ' 件 ' +
+                        '<a href="/debug/snapshots/' + snap.id + '.png" target="_blank">画像</a> ' +
+                        '<a href="/debug/snapshots/' + snap.id + '.json" target="_blank">JSON</a>';
+                    list.appendChild(entry);
+                });
+            });
+        }
+        
+        // キャリブレーション（選択範囲はキャプチャ画像のピクセル座標で保持する）
+        const calibration = {image: null, boxes: {}, dragStart: null};
+        const boxColors = {accept: 'red', matching: 'blue'};
+        
+        function captureCalibration(delaySeconds) {
+            const status = document.getElementById('calibration-status');
+            let remaining = delaySeconds;
+            const tick = () => {
+                if (remaining > 0) {
+                    status.textContent = remaining + '秒後にキャプチャします...';
+                    remaining--;
+                    setTimeout(tick, 1000);
+                    return;
+                }
+                status.textContent = 'キャプチャ中...';
+                fetch('/calibration/capture', {method: 'POST'}).then(r => {
+                    if (!r.ok) return r.text().then(t => { throw new Error(t); });
+                    return r.json();
+                }).then(data => {
+                    status.textContent = data.width + 'x' + data.height + ' をキャプチャしました';
+                    const img = new Image();
+                    img.onload = () => {
+                        calibration.image = img;
+                        calibration.boxes = {};
+                        drawCalibration();
+                    };
+                    img.src = '/calibration/frame.png?t=' + Date.now();
+                }).catch(err => { status.textContent = 'キャプチャに失敗しました: ' + err.message; });
+            };
+            tick();
+        }
+        
+        function drawCalibration() {
+            const canvas = document.getElementById('calibration-canvas');
+            const img = calibration.image;
+            canvas.style.display = 'block';
+            canvas.width = img.width;
+            canvas.height = img.height;
+            const ctx = canvas.getContext('2d');
+            ctx.drawImage(img, 0, 0);
+            ctx.lineWidth = Math.max(2, img.width / 400);
+            Object.keys(calibration.boxes).forEach(target => {
+                const b = calibration.boxes[target];
+                ctx.strokeStyle = boxColors[target];
+                ctx.strokeRect(b.x, b.y, b.w, b.h);
+            });
+        }
+        
+        function canvasPoint(event) {
+            const canvas = document.getElementById('calibration-canvas');
+            const rect = canvas.getBoundingClientRect();
+            return {
+                x: Math.round((event.clientX - rect.left) * canvas.width / rect.width),
+                y: Math.round((event.clientY - rect.top) * canvas.height / rect.height)
+            };
+        }
+        
+        const calibrationCanvas = document.getElementById('calibration-canvas');
+        calibrationCanvas.addEventListener('mousedown', event => {
+            calibration.dragStart = canvasPoint(event);
+        });
+        calibrationCanvas.addEventListener('mousemove', event => {
+            if (!calibration.dragStart) return;
+            const p = canvasPoint(event);
+            const s = calibration.dragStart;
+            const target = document.querySelector('input[name="calibration-target"]:checked').value;
+            calibration.boxes[target] = {
+                x: Math.min(s.x, p.x), y: Math.min(s.y, p.y),
+                w: Math.abs(p.x - s.x), h: Math.abs(p.y - s.y)
+            };
+            drawCalibration();
+        });
+        window.addEventListener('mouseup', () => { calibration.dragStart = null; });
+        
+        function saveCalibration() {
+            const result = document.getElementById('calibration-result');
+            if (!calibration.boxes.accept || !calibration.boxes.matching) {
+                result.textContent = '承認ボタンとマッチング表示の両方を囲んでください';
+                return;
+            }
+            result.textContent = '保存して検出をテストしています...';
+            fetch('/calibration/save', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    name: document.getElementById('calibration-name').value,
+                    accept: calibration.boxes.accept,
+                    matching: calibration.boxes.matching
+                })
+            }).then(r => {
+                if (!r.ok) return r.text().then(t => { throw new Error(t); });
+                return r.json();
+            }).then(data => {
+                const accept = data.accept ? '(' + data.accept.X + ', ' + data.accept.Y + ') ' +
+                    (data.hit ? '選択範囲内' : '選択範囲外') + ', 検証スコア ' + data.score.toFixed(3) : '検出できません';
+                result.textContent = 'パック ' + data.pack.name + ' (' + data.pack.width + 'x' + data.pack.height + ') を保存しました。' +
+                    ' マッチング画面: ' + (data.matching ? '検出' : '検出できません') + ' / 承認ボタン: ' + accept +
+                    ' (' + data.elapsed_ms + 'ms)';
+            }).catch(err => { result.textContent = '保存に失敗しました: ' + err.message; });
+        }
+        
+        function loadPacks() {
+            fetch('/api/templates/packs').then(r => r.json()).then(data => {
+                const list = document.getElementById('packs');
+                list.innerHTML = '';
+                data.packs.forEach(pack => {
+                    const base = '/api/templates/packs/' + pack.name;
+                    const active = pack.name === data.active;
+                    const entry = document.createElement('div');
+                    entry.className = 'pack' + (active ? ' active' : '');
+                    entry.innerHTML = '<img src="' + base + '/accept_button.png?t=' + Date.now() + '">' +
+                        '<img src="' + base + '/matching.png?t=' + Date.now() + '">' +
+                        '<strong>' + pack.name + '</strong> ' +
+                        (pack.builtin ? '(同梱) ' : (pack.width ? '(' + pack.width + 'x' + pack.height + ') ' : '')) +
+                        (active ? '使用中 ' : '<button class="test" onclick="activatePack(\'' + pack.name + '\')">使用する</button> ') +
+                        (active || pack.builtin ? '' : '<button class="stop" onclick="deletePack(\'' + pack.name + '\')">削除</button>');
+                    list.appendChild(entry);
+                });
+            });
+        }
+        
+        function packRequest(url, options) {
+            const status = document.getElementById('pack-status');
+            return fetch(url, options).then(r => {
+                if (!r.ok) return r.text().then(t => { throw new Error(t); });
+                status.textContent = '';
+                loadPacks();
+            }).catch(err => { status.textContent = 'エラー: ' + err.message; });
+        }
+        
+        function activatePack(name) {
+            packRequest('/api/templates/packs/' + name + '/activate', {method: 'POST'});
+        }
+        
+        function deletePack(name) {
+            if (!confirm('テンプレートパック ' + name + ' を削除しますか？')) return;
+            packRequest('/api/templates/packs/' + name, {method: 'DELETE'});
+        }
+        
+        function uploadPack(event) {
+            event.preventDefault();
+            packRequest('/api/templates/packs', {method: 'POST', body: new FormData(event.target)});
         }
         
         function clearLog() {
@@ -147,17 +614,47 @@ func (s *Server) ServeHTML(w http.ResponseWriter, r *http.Request) {
 </html>
 `
 	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(htmlContent))
+	w.Write([]byte(strings.Replace(htmlContent, "{{CONNECTION}}", s.connectionInfo(), 1)))
 }
 
 func (s *Server) SetupRoutes() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(s.authenticate)
 	r.HandleFunc("/", s.ServeHTML)
 	r.HandleFunc("/ws", s.HandleWebSocket)
-	
+	r.HandleFunc("/debug/snapshots", s.HandleSnapshotList)
+	r.Handle("/metrics", s.app.GetMetrics().Handler()).Methods("GET")
+	r.HandleFunc("/healthz", s.HandleHealthz).Methods("GET")
+	r.HandleFunc("/readyz", s.HandleReadyz).Methods("GET")
+	r.HandleFunc("/api/openapi.json", s.HandleOpenAPI).Methods("GET")
+	r.HandleFunc("/api/state", s.HandleState).Methods("GET")
+	r.HandleFunc("/api/monitoring/start", s.HandleMonitoringStart).Methods("POST")
+	r.HandleFunc("/api/monitoring/stop", s.HandleMonitoringStop).Methods("POST")
+	r.HandleFunc("/api/autowatch/start", s.HandleAutoWatchStart).Methods("POST")
+	r.HandleFunc("/api/autowatch/stop", s.HandleAutoWatchStop).Methods("POST")
+	r.HandleFunc("/api/environment/test", s.HandleEnvironmentTest).Methods("POST")
+	r.HandleFunc("/api/config", s.HandleConfig).Methods("GET")
+	r.HandleFunc("/api/config", s.HandleConfigUpdate).Methods("PATCH")
+	r.HandleFunc("/api/history", s.HandleHistory).Methods("GET")
+	r.HandleFunc("/api/history/stats", s.HandleHistoryStats).Methods("GET")
+	r.HandleFunc("/calibration/capture", s.HandleCalibrationCapture).Methods("POST")
+	r.HandleFunc("/calibration/frame.png", s.HandleCalibrationFrame).Methods("GET")
+	r.HandleFunc("/calibration/save", s.HandleCalibrationSave).Methods("POST")
+	r.HandleFunc("/api/templates/packs", s.HandleTemplatePackList).Methods("GET")
+	r.HandleFunc("/api/templates/packs", s.HandleTemplatePackUpload).Methods("POST")
+	r.HandleFunc("/api/templates/packs/{name}", s.HandleTemplatePackInfo).Methods("GET")
+	r.HandleFunc("/api/templates/packs/{name}", s.HandleTemplatePackDelete).Methods("DELETE")
+	r.HandleFunc("/api/templates/packs/{name}/activate", s.HandleTemplatePackActivate).Methods("POST")
+	r.HandleFunc("/api/templates/packs/{name}/{file}", s.HandleTemplateImage).Methods("GET")
+	r.HandleFunc("/api/templates/packs/{name}/{file}", s.HandleTemplateImageUpload).Methods("PUT")
+	if writer := s.app.GetSnapshotWriter(); writer != nil {
+		snapshotDir, _ := filepath.Abs(writer.Dir())
+		r.PathPrefix("/debug/snapshots/").Handler(http.StripPrefix("/debug/snapshots/", http.FileServer(http.Dir(snapshotDir))))
+	}
+
 	staticDir, _ := filepath.Abs("./resources")
 	r.PathPrefix("/resources/").Handler(http.StripPrefix("/resources/", http.FileServer(http.Dir(staticDir))))
-	
+
 	return r
 }
 
@@ -180,14 +677,18 @@ func (s *Server) OpenBrowser(url string) {
 
 func (s *Server) Start() error {
 	r := s.SetupRoutes()
-	
+
 	// 自動監視を開始
 	go s.app.StartAutoWatcher()
-	
+
 	go func() {
 		time.Sleep(1 * time.Second)
-		s.OpenBrowser("http://localhost:8081")
+		s.OpenBrowser(s.URL())
 	}()
-	
-	return http.ListenAndServe(":8081", r)
-}
\ No newline at end of file
+
+	if s.tlsConfig == nil {
+		return http.ListenAndServe(s.address, r)
+	}
+	httpServer := &http.Server{Addr: s.address, Handler: r, TLSConfig: s.tlsConfig}
+	return httpServer.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"lol-auto-accept/internal/app"
+	"lol-auto-accept/internal/templates"
+)
+
+// アップロードを受け付けるサイズの上限
+const (
+	maxPackUploadBytes  = 10 << 20
+	maxImageUploadBytes = 5 << 20
+)
+
+// テンプレートパックの一覧と使用中のパック
+func (s *Server) HandleTemplatePackList(w http.ResponseWriter, r *http.Request) {
+	packs, err := s.app.GetTemplateStore().List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"active": s.app.ActiveTemplatePack(),
+		"packs":  packs,
+	})
+}
+
+// テンプレートパックのアップロード（multipart: name, width, height, accept, matching）
+func (s *Server) HandleTemplatePackUpload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPackUploadBytes)
+	if err := r.ParseMultipartForm(maxPackUploadBytes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pack := templates.Pack{Name: r.FormValue("name")}
+	if !templates.ValidName(pack.Name) {
+		templateError(w, templates.ErrInvalidName)
+		return
+	}
+	if pack.Name == templates.DefaultPack {
+		templateError(w, templates.ErrBuiltin)
+		return
+	}
+	pack.Width, _ = strconv.Atoi(r.FormValue("width"))
+	pack.Height, _ = strconv.Atoi(r.FormValue("height"))
+
+	accept, err := formImage(r, "accept")
+	if err != nil {
+		http.Error(w, "accept: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	matching, err := formImage(r, "matching")
+	if err != nil {
+		http.Error(w, "matching: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.app.SaveTemplatePack(pack, accept, matching); err != nil {
+		templateError(w, err)
+		return
+	}
+	saved, err := s.app.GetTemplateStore().Info(pack.Name)
+	if err != nil {
+		templateError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// テンプレートパックの情報
+func (s *Server) HandleTemplatePackInfo(w http.ResponseWriter, r *http.Request) {
+	pack, err := s.app.GetTemplateStore().Info(mux.Vars(r)["name"])
+	if err != nil {
+		templateError(w, err)
+		return
+	}
+	writeJSON(w, pack)
+}
+
+func (s *Server) HandleTemplatePackDelete(w http.ResponseWriter, r *http.Request) {
+	if err := s.app.DeleteTemplatePack(mux.Vars(r)["name"]); err != nil {
+		templateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 使用するテンプレートパックを切り替える（監視は止めずに差し替える）
+func (s *Server) HandleTemplatePackActivate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if err := s.app.ActivateTemplatePack(name); err != nil {
+		templateError(w, err)
+		return
+	}
+	writeJSON(w, map[string]string{"active": name})
+}
+
+// テンプレート画像のプレビュー
+func (s *Server) HandleTemplateImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	path, err := s.app.GetTemplateStore().ImagePath(vars["name"], vars["file"])
+	if err != nil {
+		templateError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, path)
+}
+
+// パック内の1枚を差し替える（本文はPNG）
+func (s *Server) HandleTemplateImageUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	img, err := templates.Decode(http.MaxBytesReader(w, r.Body, maxImageUploadBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.app.SaveTemplateImage(vars["name"], vars["file"], img); err != nil {
+		templateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func formImage(r *http.Request, field string) (image.Image, error) {
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return templates.Decode(file)
+}
+
+// テンプレート操作のエラーをステータスコードに対応づける
+func templateError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, templates.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, templates.ErrInvalidName), errors.Is(err, templates.ErrInvalidFile):
+		status = http.StatusBadRequest
+	case errors.Is(err, templates.ErrBuiltin), errors.Is(err, app.ErrPackInUse):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lol-auto-accept/internal/logging"
+)
+
+// 自己署名証明書の有効期間（スマートフォンのブラウザが受け付ける上限に合わせる）
+const selfSignedValidity = 825 * 24 * time.Hour
+
+// TLS を有効にする（証明書と鍵がない場合は自己署名証明書を生成して保存する）
+func (s *Server) LoadCertificate() error {
+	cert, err := loadOrCreateCertificate(s.certFile, s.keyFile)
+	if err != nil {
+		return err
+	}
+	s.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	s.fingerprint = Fingerprint(cert.Certificate[0])
+	return nil
+}
+
+// 証明書のフィンガープリント（TLS を使わない場合は空）
+func (s *Server) Fingerprint() string {
+	return s.fingerprint
+}
+
+// Web UI に表示する接続の情報
+func (s *Server) connectionInfo() string {
+	if s.tlsConfig == nil {
+		return "HTTP（暗号化なし）"
+	}
+	return "HTTPS（証明書のフィンガープリント SHA-256: " + s.fingerprint + "）"
+}
+
+// 証明書（DER）の SHA-256 フィンガープリント（ブラウザの証明書ビューアーと同じ形式）
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+func loadOrCreateCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	switch {
+	case certErr == nil && keyErr == nil:
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("証明書の読み込み失敗: %v", err)
+		}
+		return cert, nil
+	case errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist):
+		if err := createSelfSigned(certFile, keyFile); err != nil {
+			return tls.Certificate{}, fmt.Errorf("自己署名証明書の生成失敗: %v", err)
+		}
+		logging.For(logging.ComponentServer).Info("自己署名証明書を生成しました", "cert", certFile, "key", keyFile)
+		return tls.LoadX509KeyPair(certFile, keyFile)
+	default:
+		return tls.Certificate{}, fmt.Errorf("証明書 (%s) と鍵 (%s) の片方しかありません", certFile, keyFile)
+	}
+}
+
+// このPCのホスト名とアドレスを含む自己署名証明書を生成する
+func createSelfSigned(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "LoL Auto Accept"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				template.IPAddresses = append(template.IPAddresses, ipNet.IP)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := writePEM(certFile, "CERTIFICATE", der, 0644); err != nil {
+		return err
+	}
+	return writePEM(keyFile, "EC PRIVATE KEY", keyDER, 0600)
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm)
+}
@@ -0,0 +1,240 @@
+package sim
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/synth"
+)
+
+// シミュレーター内のクライアントの画面
+type Phase string
+
+const (
+	PhaseLobby       Phase = "lobby"        // ロビー（マッチング表示なし）
+	PhaseQueue       Phase = "queue"        // キュー待ち（マッチング表示あり）
+	PhaseReadyCheck  Phase = "ready_check"  // レディチェック（マッチング表示 + 承認ボタン）
+	PhaseAccepted    Phase = "accepted"     // 承認済み、他のプレイヤー待ち
+	PhaseChampSelect Phase = "champ_select" // チャンピオン選択（終了状態）
+)
+
+// 各画面の表示時間
+type Scenario struct {
+	Lobby      time.Duration // ロビーからキューに入るまで
+	Queue      time.Duration // キューからレディチェックが出るまで
+	ReadyCheck time.Duration // レディチェックの制限時間（承認しなければ辞退扱いでキューに戻る）
+	Accepted   time.Duration // 承認から結果が出るまで
+	Declines   int           // 他のプレイヤーが辞退してキューに戻る回数
+}
+
+// 標準的な流れ（1回目のレディチェックは他のプレイヤーの辞退でキューに戻る）
+var DefaultScenario = Scenario{
+	Lobby:      5 * time.Second,
+	Queue:      10 * time.Second,
+	ReadyCheck: 12 * time.Second,
+	Accepted:   8 * time.Second,
+	Declines:   1,
+}
+
+// 表示時間を factor 倍したシナリオ
+func (s Scenario) Scaled(factor float64) Scenario {
+	scale := func(d time.Duration) time.Duration {
+		return time.Duration(float64(d) * factor)
+	}
+	s.Lobby = scale(s.Lobby)
+	s.Queue = scale(s.Queue)
+	s.ReadyCheck = scale(s.ReadyCheck)
+	s.Accepted = scale(s.Accepted)
+	return s
+}
+
+// 画面の遷移
+type Event struct {
+	Time  time.Duration // 開始からの経過時間
+	From  Phase
+	To    Phase
+	Cause string
+}
+
+// クライアントが受け取ったクリック
+type Click struct {
+	Time  time.Duration
+	Point image.Point // スクリーン座標
+	Phase Phase
+	Hit   bool // 承認ボタンの範囲内だったか
+}
+
+// シナリオどおりに画面が遷移する偽のクライアント
+// capture.Source としてフレームを返し、system.ClickTarget としてクリックを受け取る
+type Client struct {
+	clock    clock.Clock
+	scenario Scenario
+	origin   image.Point
+	pool     *capture.FramePool
+
+	// 画面ごとの描画結果（クライアント座標系）
+	screens   map[Phase]*image.RGBA
+	acceptBox image.Rectangle
+
+	mutex      sync.Mutex
+	start      time.Time
+	phase      Phase
+	phaseStart time.Time
+	declines   int
+	events     []Event
+	clicks     []Click
+}
+
+// size のクライアントがスクリーン座標 origin に表示されている状態で開始する
+func NewClient(gen *synth.Generator, clk clock.Clock, scenario Scenario, size, origin image.Point) (*Client, error) {
+	params := synth.Params{
+		Width:      size.X,
+		Height:     size.Y,
+		Background: synth.BackgroundSolid,
+		Scale:      float64(size.X) / 1280,
+		Brightness: 1,
+	}
+
+	lobby := gen.Frame(string(PhaseLobby), params)
+	params.Matching = true
+	queue := gen.Frame(string(PhaseQueue), params)
+	params.Accept = true
+	readyCheck := gen.Frame(string(PhaseReadyCheck), params)
+	if readyCheck.Label.Accept == nil {
+		return nil, fmt.Errorf("承認ボタンを描画できません: %dx%d", size.X, size.Y)
+	}
+	box := readyCheck.Label.Accept
+
+	now := clk.Now()
+	return &Client{
+		clock:    clk,
+		scenario: scenario,
+		origin:   origin,
+		pool:     capture.NewFramePool(),
+		screens: map[Phase]*image.RGBA{
+			PhaseLobby:       lobby.Image,
+			PhaseQueue:       queue.Image,
+			PhaseReadyCheck:  readyCheck.Image,
+			PhaseAccepted:    queue.Image,
+			PhaseChampSelect: lobby.Image,
+		},
+		acceptBox:  image.Rect(box.X, box.Y, box.X+box.W, box.Y+box.H),
+		start:      now,
+		phase:      PhaseLobby,
+		phaseStart: now,
+		declines:   scenario.Declines,
+	}, nil
+}
+
+// 現在の画面
+func (c *Client) Phase() Phase {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.advance()
+	return c.phase
+}
+
+// これまでの画面遷移
+func (c *Client) Events() []Event {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.advance()
+	return append([]Event(nil), c.events...)
+}
+
+// これまでに受け取ったクリック
+func (c *Client) Clicks() []Click {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]Click(nil), c.clicks...)
+}
+
+// 承認ボタンの範囲（スクリーン座標）
+func (c *Client) AcceptBox() image.Rectangle {
+	return c.acceptBox.Add(c.origin)
+}
+
+func (c *Client) Capture(region capture.Region) (*capture.Frame, error) {
+	c.mutex.Lock()
+	c.advance()
+	screen := c.screens[c.phase]
+	c.mutex.Unlock()
+
+	client := screen.Bounds()
+	roi := region.Rect(client)
+	if roi.Empty() {
+		roi = client
+	}
+
+	frame := c.pool.Get(roi)
+	draw.Draw(frame.Image, roi, screen, roi.Min, draw.Src)
+	frame.Client = client
+	frame.Origin = c.origin
+	frame.FromWindow = true
+	return frame, nil
+}
+
+func (c *Client) Click(p image.Point) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.advance()
+
+	hit := c.phase == PhaseReadyCheck && p.In(c.AcceptBox())
+	c.clicks = append(c.clicks, Click{Time: c.elapsed(), Point: p, Phase: c.phase, Hit: hit})
+	if hit {
+		c.transition(PhaseAccepted, "承認ボタンのクリック")
+	}
+}
+
+func (c *Client) elapsed() time.Duration {
+	return c.clock.Now().Sub(c.start)
+}
+
+// 時計に合わせて画面を遷移させる（mutex を保持して呼ぶこと）
+func (c *Client) advance() {
+	for {
+		var limit time.Duration
+		var next Phase
+		var cause string
+
+		switch c.phase {
+		case PhaseLobby:
+			limit, next, cause = c.scenario.Lobby, PhaseQueue, "キュー開始"
+		case PhaseQueue:
+			limit, next, cause = c.scenario.Queue, PhaseReadyCheck, "マッチング成立"
+		case PhaseReadyCheck:
+			limit, next, cause = c.scenario.ReadyCheck, PhaseQueue, "制限時間切れ（辞退）"
+		case PhaseAccepted:
+			limit, next, cause = c.scenario.Accepted, PhaseChampSelect, "全員が承認"
+			if c.declines > 0 {
+				next, cause = PhaseQueue, "他のプレイヤーが辞退"
+			}
+		default:
+			return
+		}
+
+		end := c.phaseStart.Add(limit)
+		if c.clock.Now().Before(end) {
+			return
+		}
+		if c.phase == PhaseAccepted && next == PhaseQueue {
+			c.declines--
+		}
+		c.transitionAt(next, cause, end)
+	}
+}
+
+func (c *Client) transition(next Phase, cause string) {
+	c.transitionAt(next, cause, c.clock.Now())
+}
+
+func (c *Client) transitionAt(next Phase, cause string, at time.Time) {
+	c.events = append(c.events, Event{Time: at.Sub(c.start), From: c.phase, To: next, Cause: cause})
+	c.phase = next
+	c.phaseStart = at
+}
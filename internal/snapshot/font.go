@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"image"
+	"image/color"
+)
+
+// 注釈の文字に使う 3x5 のビットマップフォント（英小文字・数字・一部の記号のみ）
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	labelPadding = 1
+)
+
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	'_': {"...", "...", "...", "...", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'a': {".#.", "#.#", "###", "#.#", "#.#"},
+	'b': {"##.", "#.#", "##.", "#.#", "##."},
+	'c': {"###", "#..", "#..", "#..", "###"},
+	'd': {"##.", "#.#", "#.#", "#.#", "##."},
+	'e': {"###", "#..", "##.", "#..", "###"},
+	'f': {"###", "#..", "##.", "#..", "#.."},
+	'g': {"###", "#..", "#.#", "#.#", "###"},
+	'h': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'i': {"###", ".#.", ".#.", ".#.", "###"},
+	'j': {"..#", "..#", "..#", "#.#", "###"},
+	'k': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'l': {"#..", "#..", "#..", "#..", "###"},
+	'm': {"#.#", "###", "###", "#.#", "#.#"},
+	'n': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'o': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'p': {"###", "#.#", "###", "#..", "#.."},
+	'q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'r': {"##.", "#.#", "##.", "#.#", "#.#"},
+	's': {".##", "#..", ".#.", "..#", "##."},
+	't': {"###", ".#.", ".#.", ".#.", ".#."},
+	'u': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'v': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'w': {"#.#", "#.#", "###", "###", "#.#"},
+	'x': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'z': {"###", "..#", ".#.", "#..", "###"},
+}
+
+var labelBackground = color.RGBA{A: 255}
+
+// 倍率 scale で描いたときのラベルの範囲（背景の余白を含む）
+func labelBounds(at image.Point, text string, scale int) image.Rectangle {
+	n := len([]rune(text))
+	w := (n*(glyphWidth+1) - 1 + 2*labelPadding) * scale
+	h := (glyphHeight + 2*labelPadding) * scale
+	return image.Rectangle{Min: at, Max: at.Add(image.Pt(w, h))}
+}
+
+// 黒い背景の上に文字列を描く（フォントにない文字は空白として扱う）
+func drawLabel(img *image.RGBA, at image.Point, text string, c color.RGBA, scale int) {
+	bounds := labelBounds(at, text, scale).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.SetRGBA(x, y, labelBackground)
+		}
+	}
+
+	origin := at.Add(image.Pt(labelPadding*scale, labelPadding*scale))
+	for i, r := range []rune(text) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		left := origin.X + i*(glyphWidth+1)*scale
+		for gy, row := range glyph {
+			for gx, bit := range row {
+				if bit != '#' {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetRGBA(left+gx*scale+dx, origin.Y+gy*scale+dy, c)
+					}
+				}
+			}
+		}
+	}
+}
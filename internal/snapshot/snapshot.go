@@ -0,0 +1,244 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/detector"
+	"lol-auto-accept/internal/logging"
+)
+
+// 判定結果
+const (
+	DecisionClicked         = "clicked"
+	DecisionSkippedLowScore = "skipped_low_score"
+	DecisionClickFailed     = "click_failed"
+)
+
+// 注釈の色
+var (
+	searchAreaColor = color.RGBA{R: 255, G: 220, B: 0, A: 255}
+	resultColor     = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	methodColors    = map[string]color.RGBA{
+		"template": {R: 0, G: 200, B: 255, A: 255},
+		"color":    {R: 0, G: 255, B: 0, A: 255},
+		"edge":     {R: 255, G: 0, B: 255, A: 255},
+	}
+)
+
+// 1回の判定の記録（JSONサイドカーの内容）
+type Snapshot struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Decision string          `json:"decision"`
+	Score    float64         `json:"score"`
+	Client   image.Rectangle `json:"client"`
+	Captured image.Rectangle `json:"captured"`
+	Origin   image.Point     `json:"origin"`
+	Trace    *detector.Trace `json:"trace"`
+}
+
+// スナップショットを保存するディレクトリ（古いものから削除）
+type Writer struct {
+	dir   string
+	max   int
+	clock clock.Clock
+	mutex sync.Mutex
+}
+
+func NewWriter(dir string, max int, clk clock.Clock) *Writer {
+	return &Writer{dir: dir, max: max, clock: clk}
+}
+
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// フレームに注釈を付けて保存（コピー・エンコード・書き込みはバックグラウンドで行う）
+func (w *Writer) Save(frame *capture.Frame, snap Snapshot) string {
+	snap.Time = w.clock.Now()
+	snap.ID = strings.ReplaceAll(snap.Time.Format("20060102-150405.000"), ".", "-") + "_" + snap.Decision
+	snap.Client = frame.Client
+	snap.Captured = frame.Image.Bounds()
+	snap.Origin = frame.Origin
+
+	frame.Retain()
+	go func() {
+		// 元のフレームはプールに戻るため注釈用にコピーしてから解放する
+		img := image.NewRGBA(frame.Image.Bounds())
+		draw.Draw(img, img.Bounds(), frame.Image, img.Bounds().Min, draw.Src)
+		frame.Release()
+
+		annotate(img, snap)
+		if err := w.write(img, snap); err != nil {
+			logging.For(logging.ComponentDetector).Warn("デバッグスナップショットの保存に失敗", "id", snap.ID, "error", err)
+		}
+	}()
+	return snap.ID
+}
+
+func (w *Writer) write(img *image.RGBA, snap Snapshot) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
+	}
+
+	pngFile, err := os.Create(filepath.Join(w.dir, snap.ID+".png"))
+	if err != nil {
+		return err
+	}
+	defer pngFile.Close()
+	if err := png.Encode(pngFile, img); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(w.dir, snap.ID+".json"), data, 0644); err != nil {
+		return err
+	}
+
+	return w.rotate()
+}
+
+// 保存数の上限を超えた古いスナップショットを削除
+func (w *Writer) rotate() error {
+	ids, err := w.ids()
+	if err != nil {
+		return err
+	}
+	for len(ids) > w.max && w.max > 0 {
+		id := ids[len(ids)-1]
+		ids = ids[:len(ids)-1]
+		os.Remove(filepath.Join(w.dir, id+".png"))
+		os.Remove(filepath.Join(w.dir, id+".json"))
+	}
+	return nil
+}
+
+// 保存済みのスナップショットID（新しい順）
+func (w *Writer) ids() ([]string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// 保存済みのスナップショット一覧（新しい順）
+func (w *Writer) List() ([]Snapshot, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	ids, err := w.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := make([]Snapshot, 0, len(ids))
+	for _, id := range ids {
+		data, err := os.ReadFile(filepath.Join(w.dir, id+".json"))
+		if err != nil {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, fmt.Errorf("スナップショットの解析失敗 (%s): %v", id, err)
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// 注釈の文字の倍率
+const (
+	candidateLabelScale = 2
+	decisionLabelScale  = 3
+)
+
+// 検索範囲・候補・最終結果を枠線で描き込み、候補のスコアと判定結果を書き添える
+func annotate(img *image.RGBA, snap Snapshot) {
+	if trace := snap.Trace; trace != nil {
+		drawBox(img, trace.SearchArea, searchAreaColor, 1)
+		for _, c := range trace.Candidates {
+			drawBox(img, c.Box, methodColors[c.Method], 1)
+		}
+		if trace.Result != nil {
+			p := image.Pt(trace.Result.X, trace.Result.Y)
+			drawBox(img, image.Rect(p.X-6, p.Y-6, p.X+7, p.Y+7), resultColor, 3)
+		}
+		// 枠線を描き終えてから文字を描く（後の候補の枠線で文字が隠れないように）
+		for _, c := range trace.Candidates {
+			drawLabel(img, candidateLabelPos(img, c, candidateLabelScale), candidateLabel(c), methodColors[c.Method], candidateLabelScale)
+		}
+	}
+
+	drawLabel(img, img.Bounds().Min, decisionLabel(snap), resultColor, decisionLabelScale)
+}
+
+// 候補のラベル（スコアと、等倍以外で一致した場合は倍率）
+func candidateLabel(c detector.Candidate) string {
+	if c.Scale != 0 && c.Scale != 1 {
+		return fmt.Sprintf("%.3f x%.2f", c.Score, c.Scale)
+	}
+	return fmt.Sprintf("%.3f", c.Score)
+}
+
+// 候補の枠の上に置く（上に余白がなければ枠の内側）
+func candidateLabelPos(img *image.RGBA, c detector.Candidate, scale int) image.Point {
+	height := labelBounds(image.Point{}, "", scale).Dy()
+	if c.Box.Min.Y-height >= img.Bounds().Min.Y {
+		return image.Pt(c.Box.Min.X, c.Box.Min.Y-height)
+	}
+	return image.Pt(c.Box.Min.X+1, c.Box.Min.Y+1)
+}
+
+// 画像の左上に書く判定結果と検証スコア
+func decisionLabel(snap Snapshot) string {
+	return fmt.Sprintf("%s %.3f", snap.Decision, snap.Score)
+}
+
+// 太さ thickness の枠線を描く
+func drawBox(img *image.RGBA, rect image.Rectangle, c color.RGBA, thickness int) {
+	for i := 0; i < thickness; i++ {
+		r := rect.Inset(i)
+		if r.Empty() {
+			return
+		}
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, r.Min.Y, c)
+			img.SetRGBA(x, r.Max.Y-1, c)
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			img.SetRGBA(r.Min.X, y, c)
+			img.SetRGBA(r.Max.X-1, y, c)
+		}
+	}
+}
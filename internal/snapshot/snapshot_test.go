@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/detector"
+)
+
+func TestSaveUsesClock(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 34, 56, 789e6, time.UTC)
+	w := NewWriter(t.TempDir(), 10, clock.NewFake(now))
+
+	frame := capture.NewFrame(image.NewRGBA(image.Rect(0, 0, 32, 18)))
+	id := w.Save(frame, Snapshot{Decision: DecisionClicked, Score: 0.9})
+	if want := "20261018-123456-789_" + DecisionClicked; id != want {
+		t.Fatalf("id = %s; want %s", id, want)
+	}
+
+	// 書き込みはバックグラウンドで行われる
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snaps, err := w.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snaps) == 1 {
+			if !snaps[0].Time.Equal(now) || !strings.HasSuffix(snaps[0].ID, DecisionClicked) {
+				t.Errorf("snapshot = %+v; want time %v", snaps[0], now)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("snapshot was not written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 範囲内に色 c の画素があるか
+func hasColor(img *image.RGBA, rect image.Rectangle, c color.RGBA) bool {
+	rect = rect.Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestAnnotateDrawsLabels(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 640, 360))
+	above := detector.Candidate{Method: detector.MethodTemplate, Box: image.Rect(100, 100, 148, 124), Score: 0.912}
+	top := detector.Candidate{Method: detector.MethodColor, Box: image.Rect(400, 0, 460, 30), Score: 0.5}
+	snap := Snapshot{
+		Decision: DecisionSkippedLowScore,
+		Score:    0.42,
+		Trace: &detector.Trace{
+			SearchArea: image.Rect(50, 60, 300, 170),
+			Candidates: []detector.Candidate{above, top},
+			Result:     &detector.Point{X: 124, Y: 112},
+		},
+	}
+	annotate(img, snap)
+
+	decision := labelBounds(image.Point{}, decisionLabel(snap), decisionLabelScale)
+	if !hasColor(img, decision, resultColor) {
+		t.Errorf("decision label %q not drawn in %v", decisionLabel(snap), decision)
+	}
+
+	aboveLabel := labelBounds(candidateLabelPos(img, above, candidateLabelScale), candidateLabel(above), candidateLabelScale)
+	if aboveLabel.Max.Y > above.Box.Min.Y {
+		t.Errorf("label %v overlaps box %v; want above it", aboveLabel, above.Box)
+	}
+	if !hasColor(img, aboveLabel, methodColors[detector.MethodTemplate]) {
+		t.Errorf("score label %q not drawn in %v", candidateLabel(above), aboveLabel)
+	}
+
+	// 上に余白がない候補は枠の内側に書く
+	topLabel := labelBounds(candidateLabelPos(img, top, candidateLabelScale), candidateLabel(top), candidateLabelScale)
+	if !topLabel.In(img.Bounds()) || topLabel.Min.Y < top.Box.Min.Y {
+		t.Errorf("label %v; want inside %v", topLabel, top.Box)
+	}
+	if !hasColor(img, topLabel, methodColors[detector.MethodColor]) {
+		t.Errorf("score label %q not drawn in %v", candidateLabel(top), topLabel)
+	}
+}
+
+func TestCandidateLabel(t *testing.T) {
+	tests := []struct {
+		c    detector.Candidate
+		want string
+	}{
+		{detector.Candidate{Score: 0.9123}, "0.912"},
+		{detector.Candidate{Score: 0.9123, Scale: 1}, "0.912"},
+		{detector.Candidate{Score: 0.75, Scale: 0.5}, "0.750 x0.50"},
+	}
+	for _, tt := range tests {
+		if got := candidateLabel(tt.c); got != tt.want {
+			t.Errorf("candidateLabel(%+v) = %q; want %q", tt.c, got, tt.want)
+		}
+	}
+}
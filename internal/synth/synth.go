@@ -0,0 +1,225 @@
+package synth
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+
+	"lol-auto-accept/internal/corpus"
+)
+
+// 背景の種類
+const (
+	BackgroundSolid    = "solid"    // クライアントに近い暗い単色
+	BackgroundGradient = "gradient" // 縦方向のグラデーション
+	BackgroundClutter  = "clutter"  // ランダムな色の矩形を敷き詰めたもの（誤検出の確認用）
+)
+
+// 1フレームの生成条件
+type Params struct {
+	Width, Height int
+	Background    string
+	Matching      bool        // マッチング画面のテンプレートを描くか
+	Accept        bool        // 承認ボタンを描くか
+	Scale         float64     // テンプレートの拡大率
+	Offset        image.Point // 既定位置からのずれ
+	Brightness    float64     // 明るさの倍率（1 で元のまま）
+	Noise         int         // 各チャンネルに加えるノイズの振れ幅
+}
+
+// 代表的なクライアントの解像度
+var resolutions = []image.Point{
+	{X: 1024, Y: 576},
+	{X: 1280, Y: 720},
+	{X: 1600, Y: 900},
+}
+
+// テンプレート画像を背景に合成してラベル付きフレームを作る
+type Generator struct {
+	accept      image.Image
+	matching    image.Image
+	rnd         *rand.Rand
+	resolutions []image.Point
+}
+
+func NewGenerator(accept, matching image.Image, seed int64) *Generator {
+	return &Generator{
+		accept:      accept,
+		matching:    matching,
+		rnd:         rand.New(rand.NewSource(seed)),
+		resolutions: resolutions,
+	}
+}
+
+// ランダムなフレームの解像度を変える（テストで小さなフレームを使うため）
+func (g *Generator) SetResolutions(res ...image.Point) {
+	g.resolutions = res
+}
+
+// resources/ のテンプレートを使う生成器
+func LoadGenerator(seed int64) (*Generator, error) {
+	accept, err := loadPNG("resources/accept_button.png")
+	if err != nil {
+		return nil, err
+	}
+	matching, err := loadPNG("resources/matching.png")
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(accept, matching, seed), nil
+}
+
+func loadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの読み込み失敗: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートのデコード失敗: %v", err)
+	}
+	return img, nil
+}
+
+// ランダムな条件のレディチェック画面（マッチング画面 + 承認ボタン）
+func (g *Generator) RandomReadyCheck(name string) corpus.Case {
+	return g.Frame(name, g.randomParams(true))
+}
+
+// テンプレートを含まない背景だけのフレーム（誤検出率の確認用）
+func (g *Generator) RandomNegative(name string) corpus.Case {
+	return g.Frame(name, g.randomParams(false))
+}
+
+func (g *Generator) randomParams(readyCheck bool) Params {
+	res := g.resolutions[g.rnd.Intn(len(g.resolutions))]
+	backgrounds := []string{BackgroundSolid, BackgroundGradient, BackgroundClutter}
+	return Params{
+		Width:      res.X,
+		Height:     res.Y,
+		Background: backgrounds[g.rnd.Intn(len(backgrounds))],
+		Matching:   readyCheck,
+		Accept:     readyCheck,
+		Scale:      float64(res.X) / 1280 * (0.9 + 0.2*g.rnd.Float64()),
+		Offset:     image.Pt(g.rnd.Intn(61)-30, g.rnd.Intn(41)-20),
+		Brightness: 0.8 + 0.4*g.rnd.Float64(),
+		Noise:      g.rnd.Intn(16),
+	}
+}
+
+// 条件どおりにフレームを生成
+func (g *Generator) Frame(name string, p Params) corpus.Case {
+	img := image.NewRGBA(image.Rect(0, 0, p.Width, p.Height))
+	g.fillBackground(img, p.Background)
+
+	label := corpus.Label{
+		Matching: p.Matching,
+		Note:     fmt.Sprintf("synthetic %dx%d %s scale=%.2f", p.Width, p.Height, p.Background, p.Scale),
+	}
+
+	// マッチング画面の表示はクライアント中央上寄り、承認ボタンは中央下寄りに置く
+	if p.Matching && g.matching != nil {
+		center := image.Pt(p.Width/2+p.Offset.X, p.Height*30/100+p.Offset.Y)
+		composite(img, g.matching, center, p.Scale)
+	}
+	if p.Accept && g.accept != nil {
+		center := image.Pt(p.Width/2+p.Offset.X, p.Height*72/100+p.Offset.Y)
+		box := composite(img, g.accept, center, p.Scale)
+		label.Accept = &corpus.Box{X: box.Min.X, Y: box.Min.Y, W: box.Dx(), H: box.Dy()}
+	}
+
+	adjust(img, p.Brightness, p.Noise, g.rnd)
+	return corpus.Case{Name: name, Image: img, Label: label}
+}
+
+func (g *Generator) fillBackground(img *image.RGBA, background string) {
+	bounds := img.Bounds()
+	switch background {
+	case BackgroundGradient:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			t := float64(y) / float64(bounds.Dy())
+			c := color.RGBA{R: uint8(1 + 20*t), G: uint8(10 + 30*t), B: uint8(19 + 50*t), A: 255}
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	case BackgroundClutter:
+		fillRect(img, bounds, color.RGBA{R: 1, G: 10, B: 19, A: 255})
+		for i := 0; i < 40; i++ {
+			w := 20 + g.rnd.Intn(bounds.Dx()/4)
+			h := 10 + g.rnd.Intn(bounds.Dy()/4)
+			x := g.rnd.Intn(bounds.Dx() - w)
+			y := g.rnd.Intn(bounds.Dy() - h)
+			c := color.RGBA{R: uint8(g.rnd.Intn(256)), G: uint8(g.rnd.Intn(256)), B: uint8(g.rnd.Intn(256)), A: 255}
+			fillRect(img, image.Rect(x, y, x+w, y+h), c)
+		}
+	default:
+		fillRect(img, bounds, color.RGBA{R: 1, G: 10, B: 19, A: 255})
+	}
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	rect = rect.Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// テンプレートを center を中心に scale 倍で描き、描いた範囲を返す（最近傍補間）
+func composite(dst *image.RGBA, src image.Image, center image.Point, scale float64) image.Rectangle {
+	srcBounds := src.Bounds()
+	w := int(float64(srcBounds.Dx()) * scale)
+	h := int(float64(srcBounds.Dy()) * scale)
+	box := image.Rect(center.X-w/2, center.Y-h/2, center.X-w/2+w, center.Y-h/2+h).Intersect(dst.Bounds())
+
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		sy := srcBounds.Min.Y + int(float64(y-(center.Y-h/2))/scale)
+		for x := box.Min.X; x < box.Max.X; x++ {
+			sx := srcBounds.Min.X + int(float64(x-(center.X-w/2))/scale)
+			r, g, b, a := src.At(sx, sy).RGBA()
+			if a == 0 {
+				continue
+			}
+			// アルファ値で背景と合成
+			bg := dst.RGBAAt(x, y)
+			alpha := float64(a) / 0xffff
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(float64(r>>8) + float64(bg.R)*(1-alpha)),
+				G: uint8(float64(g>>8) + float64(bg.G)*(1-alpha)),
+				B: uint8(float64(b>>8) + float64(bg.B)*(1-alpha)),
+				A: 255,
+			})
+		}
+	}
+	return box
+}
+
+// 明るさの変更とノイズの付加
+func adjust(img *image.RGBA, brightness float64, noise int, rnd *rand.Rand) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		for ch := 0; ch < 3; ch++ {
+			v := float64(img.Pix[i+ch]) * brightness
+			if noise > 0 {
+				v += float64(rnd.Intn(2*noise+1) - noise)
+			}
+			img.Pix[i+ch] = clamp(v)
+		}
+	}
+}
+
+func clamp(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -0,0 +1,145 @@
+package synth
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"lol-auto-accept/internal/capture"
+	"lol-auto-accept/internal/detector"
+)
+
+// クリックに至る誤検出の許容上限（テンプレートなしのフレームに対する割合）
+// 現状はシード 1 の 20 枚中 3 枚（いずれも clutter 背景）で、これより悪化したら失敗させる
+const maxFalsePositiveRate = 0.15
+
+func loadGenerator(t *testing.T, seed int64) *Generator {
+	t.Helper()
+	accept, err := loadPNG(filepath.Join("..", "..", "resources", "accept_button.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matching, err := loadPNG(filepath.Join("..", "..", "resources", "matching.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGenerator(accept, matching, seed)
+}
+
+// 同じシードからは同じフレームとラベルが得られる
+func TestGeneratorDeterministic(t *testing.T) {
+	a, b, other := loadGenerator(t, 7), loadGenerator(t, 7), loadGenerator(t, 8)
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("case_%d", i)
+		ca, cb, co := a.RandomReadyCheck(name), b.RandomReadyCheck(name), other.RandomReadyCheck(name)
+		if ca.Label.Note != cb.Label.Note || *ca.Label.Accept != *cb.Label.Accept {
+			t.Errorf("#%d label = %+v; want %+v", i, cb.Label, ca.Label)
+		}
+		if !bytes.Equal(ca.Image.Pix, cb.Image.Pix) {
+			t.Errorf("#%d frames differ for the same seed", i)
+		}
+		if bytes.Equal(ca.Image.Pix, co.Image.Pix) {
+			t.Errorf("#%d frames are equal for different seeds", i)
+		}
+
+		na, nb := a.RandomNegative(name), b.RandomNegative(name)
+		if !bytes.Equal(na.Image.Pix, nb.Image.Pix) {
+			t.Errorf("#%d negative frames differ for the same seed", i)
+		}
+	}
+}
+
+// 承認ボタンのラベルは、生成した拡大率でのボタンの範囲と一致してフレーム内に収まる
+func TestReadyCheckLabel(t *testing.T) {
+	g := loadGenerator(t, 1)
+	size := g.accept.Bounds().Size()
+	for i := 0; i < 20; i++ {
+		p := g.randomParams(true)
+		c := g.Frame(fmt.Sprintf("case_%d", i), p)
+
+		if !c.Label.Matching || c.Label.Accept == nil {
+			t.Fatalf("#%d label = %+v; want matching with an accept button", i, c.Label)
+		}
+		box := c.Label.Accept
+		rect := image.Rect(box.X, box.Y, box.X+box.W, box.Y+box.H)
+		if !rect.In(c.Image.Bounds()) {
+			t.Errorf("#%d accept = %v; want inside %v", i, rect, c.Image.Bounds())
+		}
+		wantW, wantH := int(float64(size.X)*p.Scale), int(float64(size.Y)*p.Scale)
+		if box.W != wantW || box.H != wantH {
+			t.Errorf("#%d accept size = %dx%d; want %dx%d (scale %.2f)", i, box.W, box.H, wantW, wantH, p.Scale)
+		}
+		center := image.Pt(p.Width/2+p.Offset.X, p.Height*72/100+p.Offset.Y)
+		if got := rect.Min.Add(rect.Size().Div(2)); got != center {
+			t.Errorf("#%d accept center = %v; want %v", i, got, center)
+		}
+	}
+
+	n := g.RandomNegative("negative")
+	if n.Label.Matching || n.Label.Accept != nil {
+		t.Errorf("negative label = %+v; want no matching screen and no accept button", n.Label)
+	}
+}
+
+// 2色の縦縞のテンプレート（背景と取り違えない配色にする）
+func stripes(w, h, width int, a, b color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := a
+			if (x/width)%2 == 1 {
+				c = b
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// テンプレートなしのフレームを検出器にかけ、クリックに至る誤検出の割合が上限以下であることを確認する
+// 監視ループはマッチング画面と承認ボタンを同じフレームで検出したときだけクリックする
+// 検出を速くするため、シミュレーターのテストと同じく 320x180 のフレームと縦縞のテンプレートを使う
+// （resources/ のテンプレートでの精度は testdata/corpus の基準で確認する）
+func TestNegativesFalsePositiveRate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("detection over generated frames is slow")
+	}
+
+	accept := stripes(48, 16, 4, color.RGBA{R: 230, G: 200, B: 40, A: 255}, color.RGBA{R: 20, G: 90, B: 200, A: 255})
+	matching := stripes(64, 16, 4, color.RGBA{R: 200, G: 40, B: 180, A: 255}, color.RGBA{R: 40, G: 190, B: 60, A: 255})
+	d := detector.NewImageDetector()
+	d.SetTemplates(accept, matching)
+
+	// 生成時の拡大率は 1280 幅基準のため、4倍のテンプレートを 320 幅のフレームに描く
+	g := NewGenerator(
+		stripes(192, 64, 16, color.RGBA{R: 230, G: 200, B: 40, A: 255}, color.RGBA{R: 20, G: 90, B: 200, A: 255}),
+		stripes(256, 64, 16, color.RGBA{R: 200, G: 40, B: 180, A: 255}, color.RGBA{R: 40, G: 190, B: 60, A: 255}),
+		1)
+	g.SetResolutions(image.Pt(320, 180))
+
+	const frames = 20
+	var matched, buttons, clicks int
+	for i := 0; i < frames; i++ {
+		c := g.RandomNegative(fmt.Sprintf("negative_%03d", i))
+		frame := capture.NewFrame(c.Image)
+		m := d.Clone().FastDetectMatchingScreen(frame)
+		pos := d.Clone().FastDetectAcceptButton(frame)
+		if m {
+			matched++
+		}
+		if pos != nil {
+			buttons++
+		}
+		if m && pos != nil {
+			clicks++
+			t.Logf("NG %s: %s (%s)", c.Name, c.Label.Note, pos.Method)
+		}
+	}
+	t.Logf("false positives in %d frames: matching %d, accept %d, click %d", frames, matched, buttons, clicks)
+	if rate := float64(clicks) / frames; rate > maxFalsePositiveRate {
+		t.Errorf("false positive click rate = %.2f; want <= %.2f", rate, maxFalsePositiveRate)
+	}
+}
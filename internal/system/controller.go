@@ -1,19 +1,76 @@
 package system
 
 import (
-	"fmt"
-	"os/exec"
+	"image"
+	"math/rand"
 	"runtime"
+	"sync"
 	"time"
+
+	"lol-auto-accept/internal/clock"
+	"lol-auto-accept/internal/logging"
 )
 
+// OSごとのマウス操作の実装
+type backend interface {
+	cursorPosition() (image.Point, error)
+	click(path []pathStep) error
+	available() bool
+}
+
 type Controller struct {
-	osType string
+	osType  string
+	backend backend
+	motion  MotionConfig
+	rnd     *rand.Rand
+	mutex   sync.Mutex
 }
 
 func NewController() *Controller {
-	return &Controller{
+	c := &Controller{
 		osType: runtime.GOOS,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if c.osType == "windows" {
+		c.backend = &powershellBackend{}
+	} else {
+		c.backend = &xdotoolBackend{}
+	}
+	return c
+}
+
+// マウスを動かさずにクリック予定位置を記録するだけのコントローラー
+func NewDryRunController() *Controller {
+	return &Controller{
+		osType:  runtime.GOOS,
+		backend: &dryRunBackend{clock: clock.Real},
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (c *Controller) IsDryRun() bool {
+	_, ok := c.backend.(*dryRunBackend)
+	return ok
+}
+
+// ドライランで記録されたクリック一覧（通常モードでは常に空）
+func (c *Controller) RecordedClicks() []ClickRecord {
+	switch b := c.backend.(type) {
+	case *dryRunBackend:
+		return b.recorded()
+	case *fakeBackend:
+		return b.recorded()
+	}
+	return nil
+}
+
+// ドライランで記録するクリックの時刻に使う時計（既定は実時間）
+func (c *Controller) SetClock(clk clock.Clock) {
+	switch b := c.backend.(type) {
+	case *dryRunBackend:
+		b.setClock(clk)
+	case *fakeBackend:
+		b.setClock(clk)
 	}
 }
 
@@ -21,62 +78,53 @@ func (c *Controller) GetOSName() string {
 	return c.osType
 }
 
-func (c *Controller) ClickAcceptButton(x, y int) bool {
-	var err error
-	
-	if c.osType == "windows" {
-		err = c.clickWindows(x, y)
-	} else {
-		err = c.clickUnix(x, y)
-	}
-	
-	return err == nil
+func (c *Controller) SetMotion(motion MotionConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.motion = motion
 }
 
-func (c *Controller) clickWindows(x, y int) error {
-	script := fmt.Sprintf(`
-Add-Type -AssemblyName System.Windows.Forms
-[System.Windows.Forms.Cursor]::Position = [System.Drawing.Point]::new(%d, %d)
-Start-Sleep -Milliseconds 50
-Add-Type -TypeDefinition '
-using System;
-using System.Runtime.InteropServices;
-public class Mouse {
-    [DllImport("user32.dll")]
-    public static extern void mouse_event(uint dwFlags, uint dx, uint dy, uint dwData, IntPtr dwExtraInfo);
-    public const uint MOUSEEVENTF_LEFTDOWN = 0x02;
-    public const uint MOUSEEVENTF_LEFTUP = 0x04;
+func (c *Controller) GetMotion() MotionConfig {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.motion
 }
-'
-[Mouse]::mouse_event([Mouse]::MOUSEEVENTF_LEFTDOWN, 0, 0, 0, [IntPtr]::Zero)
-Start-Sleep -Milliseconds 50
-[Mouse]::mouse_event([Mouse]::MOUSEEVENTF_LEFTUP, 0, 0, 0, [IntPtr]::Zero)
-`, x, y)
-
-	cmd := exec.Command("powershell", "-Command", script)
-	return cmd.Run()
+
+func (c *Controller) ClickAcceptButton(x, y int) bool {
+	return c.clickAt(image.Pt(x, y))
 }
 
-func (c *Controller) clickUnix(x, y int) error {
-	cmd := exec.Command("xdotool", "mousemove", fmt.Sprintf("%d", x), fmt.Sprintf("%d", y))
-	err := cmd.Run()
-	if err != nil {
-		return err
+// 検出されたボタン範囲内をクリック（humanize有効時は中心からランダムにずらす）
+// 実際にクリックした位置を返す
+func (c *Controller) ClickAcceptButtonInBounds(bounds image.Rectangle) (image.Point, bool) {
+	c.mutex.Lock()
+	target := jitterTarget(bounds, c.motion.Jitter, c.rnd)
+	if !c.motion.Humanize {
+		target = image.Pt((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)
 	}
+	c.mutex.Unlock()
 
-	time.Sleep(50 * time.Millisecond)
+	return target, c.clickAt(target)
+}
 
-	cmd = exec.Command("xdotool", "click", "1")
-	return cmd.Run()
+func (c *Controller) clickAt(target image.Point) bool {
+	motion := c.GetMotion()
+
+	path := []pathStep{{X: target.X, Y: target.Y}}
+	if motion.Humanize {
+		// 現在位置が取得できない場合は瞬時に移動
+		if from, err := c.backend.cursorPosition(); err == nil {
+			path = planPath(from, target, motion)
+		}
+	}
+
+	if err := c.backend.click(path); err != nil {
+		logging.For(logging.ComponentSystem).Warn("クリック失敗", "x", target.X, "y", target.Y, "error", err)
+		return false
+	}
+	return true
 }
 
 func (c *Controller) IsSystemSupported() bool {
-	if c.osType == "windows" {
-		return true
-	} else {
-		// xdotoolが利用可能かチェック
-		cmd := exec.Command("which", "xdotool")
-		err := cmd.Run()
-		return err == nil
-	}
-}
\ No newline at end of file
+	return c.backend.available()
+}
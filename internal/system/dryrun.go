@@ -0,0 +1,61 @@
+package system
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+// ドライラン時に記録するクリック数の上限
+const maxRecordedClicks = 100
+
+// 記録されたクリック
+type ClickRecord struct {
+	X, Y int
+	Time time.Time
+}
+
+// 実際にはマウスを操作せず、クリック予定位置を記録するだけのバックエンド
+type dryRunBackend struct {
+	mutex  sync.Mutex
+	clock  clock.Clock
+	cursor image.Point
+	clicks []ClickRecord
+}
+
+func (b *dryRunBackend) setClock(clk clock.Clock) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.clock = clk
+}
+
+func (b *dryRunBackend) cursorPosition() (image.Point, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.cursor, nil
+}
+
+func (b *dryRunBackend) click(path []pathStep) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	last := path[len(path)-1]
+	b.cursor = image.Pt(last.X, last.Y)
+	b.clicks = append(b.clicks, ClickRecord{X: last.X, Y: last.Y, Time: b.clock.Now()})
+	if len(b.clicks) > maxRecordedClicks {
+		b.clicks = b.clicks[len(b.clicks)-maxRecordedClicks:]
+	}
+	return nil
+}
+
+func (b *dryRunBackend) available() bool {
+	return true
+}
+
+func (b *dryRunBackend) recorded() []ClickRecord {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]ClickRecord(nil), b.clicks...)
+}
@@ -0,0 +1,32 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+func TestDryRunRecordsClockTime(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	c := NewDryRunController()
+	c.SetClock(clk)
+
+	if !c.ClickAcceptButton(10, 20) {
+		t.Fatal("dry run click failed")
+	}
+	clk.Advance(3 * time.Second)
+	c.ClickAcceptButton(30, 40)
+
+	clicks := c.RecordedClicks()
+	if len(clicks) != 2 {
+		t.Fatalf("clicks = %d; want 2", len(clicks))
+	}
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if !clicks[0].Time.Equal(start) || !clicks[1].Time.Equal(start.Add(3*time.Second)) {
+		t.Errorf("click times = %v, %v; want the fake clock", clicks[0].Time, clicks[1].Time)
+	}
+	if clicks[1].X != 30 || clicks[1].Y != 40 {
+		t.Errorf("click = %+v; want (30, 40)", clicks[1])
+	}
+}
@@ -0,0 +1,39 @@
+package system
+
+import (
+	"image"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"lol-auto-accept/internal/clock"
+)
+
+// クリックを受け取る相手（シミュレーターのクライアントなど）
+type ClickTarget interface {
+	Click(p image.Point)
+}
+
+// マウスを動かさず、クリックを target に届けるバックエンド
+type fakeBackend struct {
+	dryRunBackend
+	target ClickTarget
+}
+
+func (b *fakeBackend) click(path []pathStep) error {
+	if err := b.dryRunBackend.click(path); err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	b.target.Click(image.Pt(last.X, last.Y))
+	return nil
+}
+
+// クリックを target に届けるコントローラー（統合テスト用、ドライラン扱いにはならない）
+func NewFakeController(target ClickTarget) *Controller {
+	return &Controller{
+		osType:  runtime.GOOS,
+		backend: &fakeBackend{dryRunBackend: dryRunBackend{clock: clock.Real}, target: target},
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
@@ -0,0 +1,108 @@
+package system
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// 移動経路の1ステップあたりの間隔（約60Hz）
+const motionStepInterval = 16 * time.Millisecond
+
+// マウス移動の設定
+type MotionConfig struct {
+	Humanize bool          // false の場合は目標位置へ瞬時に移動
+	Duration time.Duration // 移動にかける時間
+	Easing   string        // "linear" / "ease-in" / "ease-out" / "ease-in-out"
+	Jitter   float64       // ボタン範囲内でのランダムなずれ（0〜1、半径に対する割合）
+}
+
+// 移動経路上の1点
+type pathStep struct {
+	X, Y  int
+	Delay time.Duration // この点に移動した後の待機時間
+}
+
+// イージング関数（0〜1 の進捗を変換）
+func easingFunc(name string) func(t float64) float64 {
+	switch name {
+	case "linear":
+		return func(t float64) float64 { return t }
+	case "ease-in":
+		return func(t float64) float64 { return t * t * t }
+	case "ease-out":
+		return func(t float64) float64 {
+			u := 1 - t
+			return 1 - u*u*u
+		}
+	default: // ease-in-out
+		return func(t float64) float64 {
+			if t < 0.5 {
+				return 4 * t * t * t
+			}
+			u := -2*t + 2
+			return 1 - u*u*u/2
+		}
+	}
+}
+
+// 開始位置から目標位置までの移動経路を生成
+func planPath(from, to image.Point, cfg MotionConfig) []pathStep {
+	if !cfg.Humanize || cfg.Duration <= 0 || from == to {
+		return []pathStep{{X: to.X, Y: to.Y}}
+	}
+
+	steps := int(cfg.Duration / motionStepInterval)
+	if steps < 2 {
+		steps = 2
+	}
+
+	ease := easingFunc(cfg.Easing)
+	dx := float64(to.X - from.X)
+	dy := float64(to.Y - from.Y)
+
+	path := make([]pathStep, 0, steps)
+	for i := 1; i <= steps; i++ {
+		p := ease(float64(i) / float64(steps))
+		path = append(path, pathStep{
+			X:     from.X + int(math.Round(dx*p)),
+			Y:     from.Y + int(math.Round(dy*p)),
+			Delay: motionStepInterval,
+		})
+	}
+	// 最終点は必ず目標位置に一致させる
+	path[len(path)-1] = pathStep{X: to.X, Y: to.Y}
+	return path
+}
+
+// ボタン範囲内でクリック位置を決定（中心からランダムにずらす）
+func jitterTarget(bounds image.Rectangle, ratio float64, rnd *rand.Rand) image.Point {
+	center := image.Pt((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)
+	if ratio <= 0 || bounds.Empty() {
+		return center
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	rx := float64(bounds.Dx()) / 2 * ratio
+	ry := float64(bounds.Dy()) / 2 * ratio
+	x := center.X + int(math.Round((rnd.Float64()*2-1)*rx))
+	y := center.Y + int(math.Round((rnd.Float64()*2-1)*ry))
+
+	// 範囲外に出ないように補正
+	if x < bounds.Min.X {
+		x = bounds.Min.X
+	}
+	if x >= bounds.Max.X {
+		x = bounds.Max.X - 1
+	}
+	if y < bounds.Min.Y {
+		y = bounds.Min.Y
+	}
+	if y >= bounds.Max.Y {
+		y = bounds.Max.Y - 1
+	}
+	return image.Pt(x, y)
+}
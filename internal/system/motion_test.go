@@ -0,0 +1,121 @@
+package system
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPlanPath(t *testing.T) {
+	from, to := image.Pt(10, 20), image.Pt(410, 320)
+	tests := []struct {
+		name      string
+		cfg       MotionConfig
+		from      image.Point
+		wantSteps int
+	}{
+		{"humanize off", MotionConfig{Humanize: false, Duration: 250 * time.Millisecond}, from, 1},
+		{"zero duration", MotionConfig{Humanize: true}, from, 1},
+		{"already there", MotionConfig{Humanize: true, Duration: 250 * time.Millisecond}, to, 1},
+		{"250ms", MotionConfig{Humanize: true, Duration: 250 * time.Millisecond, Easing: "linear"}, from, 15},
+		{"1s", MotionConfig{Humanize: true, Duration: time.Second, Easing: "ease-in-out"}, from, 62},
+		{"shorter than a step", MotionConfig{Humanize: true, Duration: 5 * time.Millisecond, Easing: "ease-out"}, from, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := planPath(tt.from, to, tt.cfg)
+			if len(path) != tt.wantSteps {
+				t.Errorf("len(path) = %d; want %d", len(path), tt.wantSteps)
+			}
+			last := path[len(path)-1]
+			if got := image.Pt(last.X, last.Y); got != to {
+				t.Errorf("last point = %v; want %v", got, to)
+			}
+			if last.Delay != 0 {
+				t.Errorf("last delay = %v; want 0", last.Delay)
+			}
+		})
+	}
+}
+
+func TestEasingFunc(t *testing.T) {
+	for _, name := range []string{"linear", "ease-in", "ease-out", "ease-in-out", "unknown"} {
+		ease := easingFunc(name)
+		if got := ease(0); got != 0 {
+			t.Errorf("%s(0) = %v; want 0", name, got)
+		}
+		if got := ease(1); got != 1 {
+			t.Errorf("%s(1) = %v; want 1", name, got)
+		}
+		prev := 0.0
+		for i := 1; i <= 100; i++ {
+			x := float64(i) / 100
+			got := ease(x)
+			if got < prev {
+				t.Errorf("%s(%v) = %v; want >= %v (monotone)", name, x, got, prev)
+				break
+			}
+			prev = got
+		}
+	}
+}
+
+func TestJitterTargetInsideBounds(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+		ratio  float64
+	}{
+		{"button", image.Rect(100, 200, 220, 240), 0.3},
+		{"full ratio", image.Rect(100, 200, 220, 240), 1},
+		{"ratio above 1", image.Rect(100, 200, 220, 240), 5},
+		{"1x1", image.Rect(50, 60, 51, 61), 1},
+		{"1px wide", image.Rect(50, 60, 51, 90), 1},
+		{"2x2", image.Rect(50, 60, 52, 62), 1},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 1000; i++ {
+			if p := jitterTarget(tt.bounds, tt.ratio, rnd); !p.In(tt.bounds) {
+				t.Errorf("%s: jitterTarget(%v, %v) = %v; want inside", tt.name, tt.bounds, tt.ratio, p)
+				break
+			}
+		}
+	}
+}
+
+// 大きさのない範囲やずれなしの場合は中心
+func TestJitterTargetCenter(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	tests := []struct {
+		bounds image.Rectangle
+		ratio  float64
+		want   image.Point
+	}{
+		{image.Rect(100, 200, 220, 240), 0, image.Pt(160, 220)},
+		{image.Rect(50, 60, 50, 90), 1, image.Pt(50, 75)},
+		{image.Rect(50, 60, 50, 60), 1, image.Pt(50, 60)},
+	}
+	for _, tt := range tests {
+		if got := jitterTarget(tt.bounds, tt.ratio, rnd); got != tt.want {
+			t.Errorf("jitterTarget(%v, %v) = %v; want %v", tt.bounds, tt.ratio, got, tt.want)
+		}
+	}
+}
+
+// humanize が無効ならずれの設定があってもボタンの中心をクリックする
+func TestClickInBoundsWithoutHumanize(t *testing.T) {
+	c := NewDryRunController()
+	c.SetMotion(MotionConfig{Humanize: false, Jitter: 1})
+	bounds := image.Rect(100, 200, 220, 240)
+	for i := 0; i < 20; i++ {
+		target, ok := c.ClickAcceptButtonInBounds(bounds)
+		if !ok {
+			t.Fatal("dry run click failed")
+		}
+		if want := image.Pt(160, 220); target != want {
+			t.Fatalf("target = %v; want %v", target, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package system
+
+import (
+	"fmt"
+	"image"
+	"os/exec"
+	"strings"
+)
+
+// PowerShell経由のマウス制御（Windows）
+type powershellBackend struct{}
+
+func (b *powershellBackend) cursorPosition() (image.Point, error) {
+	script := `
+Add-Type -AssemblyName System.Windows.Forms
+$p = [System.Windows.Forms.Cursor]::Position
+Write-Output "$($p.X) $($p.Y)"
+`
+	out, err := exec.Command("powershell", "-Command", script).Output()
+	if err != nil {
+		return image.Point{}, err
+	}
+
+	var p image.Point
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d", &p.X, &p.Y); err != nil {
+		return image.Point{}, fmt.Errorf("カーソル位置の解析失敗: %v", err)
+	}
+	return p, nil
+}
+
+func (b *powershellBackend) click(path []pathStep) error {
+	var moves strings.Builder
+	for _, step := range path {
+		fmt.Fprintf(&moves, "[System.Windows.Forms.Cursor]::Position = [System.Drawing.Point]::new(%d, %d)\n", step.X, step.Y)
+		if step.Delay > 0 {
+			fmt.Fprintf(&moves, "Start-Sleep -Milliseconds %d\n", step.Delay.Milliseconds())
+		}
+	}
+
+	script := fmt.Sprintf(`
+Add-Type -AssemblyName System.Windows.Forms
+%sStart-Sleep -Milliseconds 50
+Add-Type -TypeDefinition '
+using System;
+using System.Runtime.InteropServices;
+public class Mouse {
+    [DllImport("user32.dll")]
+    public static extern void mouse_event(uint dwFlags, uint dx, uint dy, uint dwData, IntPtr dwExtraInfo);
+    public const uint MOUSEEVENTF_LEFTDOWN = 0x02;
+    public const uint MOUSEEVENTF_LEFTUP = 0x04;
+}
+'
+[Mouse]::mouse_event([Mouse]::MOUSEEVENTF_LEFTDOWN, 0, 0, 0, [IntPtr]::Zero)
+Start-Sleep -Milliseconds 50
+[Mouse]::mouse_event([Mouse]::MOUSEEVENTF_LEFTUP, 0, 0, 0, [IntPtr]::Zero)
+`, moves.String())
+
+	cmd := exec.Command("powershell", "-Command", script)
+	return cmd.Run()
+}
+
+func (b *powershellBackend) available() bool {
+	return true
+}
@@ -0,0 +1,60 @@
+package system
+
+import (
+	"fmt"
+	"image"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// xdotool経由のマウス制御（Linux/macOS）
+type xdotoolBackend struct{}
+
+func (b *xdotoolBackend) cursorPosition() (image.Point, error) {
+	out, err := exec.Command("xdotool", "getmouselocation", "--shell").Output()
+	if err != nil {
+		return image.Point{}, err
+	}
+
+	// 出力例: X=123\nY=456\nSCREEN=0\nWINDOW=789
+	var p image.Point
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "X":
+			p.X, err = strconv.Atoi(value)
+		case "Y":
+			p.Y, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return image.Point{}, fmt.Errorf("カーソル位置の解析失敗: %v", err)
+		}
+	}
+	return p, nil
+}
+
+func (b *xdotoolBackend) click(path []pathStep) error {
+	// 経路全体を1回のxdotool呼び出しにまとめる
+	args := make([]string, 0, len(path)*5+4)
+	for _, step := range path {
+		args = append(args, "mousemove", strconv.Itoa(step.X), strconv.Itoa(step.Y))
+		if step.Delay > 0 {
+			args = append(args, "sleep", strconv.FormatFloat(step.Delay.Seconds(), 'f', 3, 64))
+		}
+	}
+	args = append(args, "sleep", "0.05", "click", "1")
+
+	cmd := exec.Command("xdotool", args...)
+	return cmd.Run()
+}
+
+func (b *xdotoolBackend) available() bool {
+	// xdotoolが利用可能かチェック
+	cmd := exec.Command("which", "xdotool")
+	err := cmd.Run()
+	return err == nil
+}
@@ -0,0 +1,302 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// パック内のファイル名
+const (
+	AcceptFile   = "accept_button.png"
+	MatchingFile = "matching.png"
+	metaFile     = "pack.json"
+)
+
+// 同梱のテンプレート（resources/）を表すパック名
+const DefaultPack = "default"
+
+// テンプレート画像として受け付けるサイズ
+const (
+	MinTemplateSize   = 8
+	MaxTemplateWidth  = 1024
+	MaxTemplateHeight = 512
+)
+
+// パック名に使える文字（ディレクトリ名になるため制限する）
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var (
+	ErrInvalidName = errors.New("パック名は英数字・ハイフン・アンダースコア (64文字以内) で指定してください")
+	ErrInvalidFile = errors.New("テンプレートのファイル名は accept_button.png または matching.png です")
+	ErrBuiltin     = errors.New("同梱のテンプレートは変更できません")
+	ErrNotFound    = errors.New("テンプレートパックが見つかりません")
+)
+
+// テンプレートパックの情報（pack.json の内容）
+type Pack struct {
+	Name    string    `json:"name"`
+	Width   int       `json:"width"`  // 作成時のクライアントの幅（不明な場合は 0）
+	Height  int       `json:"height"` // 作成時のクライアントの高さ（不明な場合は 0）
+	Created time.Time `json:"created"`
+	Builtin bool      `json:"builtin"`
+}
+
+// テンプレートパックを保存するディレクトリ（パックごとにサブディレクトリを作る）
+type Store struct {
+	dir        string
+	builtinDir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, builtinDir: "resources"}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+func ValidFile(file string) bool {
+	return file == AcceptFile || file == MatchingFile
+}
+
+// テンプレート画像として使えるサイズか確認する
+func Validate(img image.Image) error {
+	b := img.Bounds()
+	return validateSize(b.Dx(), b.Dy())
+}
+
+func validateSize(width, height int) error {
+	if width < MinTemplateSize || height < MinTemplateSize {
+		return fmt.Errorf("テンプレートが小さすぎます (%dx%d、%dx%d ピクセル以上が必要です)", width, height, MinTemplateSize, MinTemplateSize)
+	}
+	if width > MaxTemplateWidth || height > MaxTemplateHeight {
+		return fmt.Errorf("テンプレートが大きすぎます (%dx%d、%dx%d ピクセル以下にしてください)", width, height, MaxTemplateWidth, MaxTemplateHeight)
+	}
+	return nil
+}
+
+// アップロードされたPNGを読み込んでサイズを確認する
+// 巨大な画像でメモリを使い切らないよう、ヘッダーのサイズを確認してから画素を読み込む
+func Decode(r io.Reader) (image.Image, error) {
+	var header bytes.Buffer
+	cfg, err := png.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, fmt.Errorf("PNGとして読み込めません: %v", err)
+	}
+	if err := validateSize(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, fmt.Errorf("PNGとして読み込めません: %v", err)
+	}
+	return img, nil
+}
+
+func (s *Store) packDir(name string) (string, error) {
+	if name == DefaultPack {
+		return s.builtinDir, nil
+	}
+	if !ValidName(name) {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// パックを保存する（同名のパックは上書き）
+func (s *Store) Save(pack Pack, accept, matching image.Image) error {
+	if !ValidName(pack.Name) {
+		return ErrInvalidName
+	}
+	if pack.Name == DefaultPack {
+		return ErrBuiltin
+	}
+	for _, img := range []image.Image{accept, matching} {
+		if err := Validate(img); err != nil {
+			return err
+		}
+	}
+	pack.Builtin = false
+	dir := filepath.Join(s.dir, pack.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("テンプレートパックの作成失敗: %v", err)
+	}
+
+	if err := writePNG(filepath.Join(dir, AcceptFile), accept); err != nil {
+		return err
+	}
+	if err := writePNG(filepath.Join(dir, MatchingFile), matching); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, metaFile), data, 0644)
+}
+
+// パック内の1枚を差し替える
+func (s *Store) SaveImage(name, file string, img image.Image) error {
+	if name == DefaultPack {
+		return ErrBuiltin
+	}
+	if !ValidFile(file) {
+		return ErrInvalidFile
+	}
+	if err := Validate(img); err != nil {
+		return err
+	}
+	if _, err := s.Info(name); err != nil {
+		return err
+	}
+	dir, err := s.packDir(name)
+	if err != nil {
+		return err
+	}
+	return writePNG(filepath.Join(dir, file), img)
+}
+
+// パックを削除する
+func (s *Store) Delete(name string) error {
+	if name == DefaultPack {
+		return ErrBuiltin
+	}
+	if _, err := s.Info(name); err != nil {
+		return err
+	}
+	dir, err := s.packDir(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// パックの情報
+func (s *Store) Info(name string) (Pack, error) {
+	if name == DefaultPack {
+		return Pack{Name: DefaultPack, Builtin: true}, nil
+	}
+	dir, err := s.packDir(name)
+	if err != nil {
+		return Pack{}, err
+	}
+	return readMeta(dir)
+}
+
+// パック内の1枚のPNGファイルのパス（プレビュー用）
+func (s *Store) ImagePath(name, file string) (string, error) {
+	if !ValidFile(file) {
+		return "", ErrInvalidFile
+	}
+	if _, err := s.Info(name); err != nil {
+		return "", err
+	}
+	dir, err := s.packDir(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// パックのテンプレート画像を読み込む
+func (s *Store) Load(name string) (Pack, image.Image, image.Image, error) {
+	pack, err := s.Info(name)
+	if err != nil {
+		return Pack{}, nil, nil, err
+	}
+	dir, err := s.packDir(name)
+	if err != nil {
+		return Pack{}, nil, nil, err
+	}
+	accept, err := readPNG(filepath.Join(dir, AcceptFile))
+	if err != nil {
+		return Pack{}, nil, nil, err
+	}
+	matching, err := readPNG(filepath.Join(dir, MatchingFile))
+	if err != nil {
+		return Pack{}, nil, nil, err
+	}
+	return pack, accept, matching, nil
+}
+
+// パック一覧（同梱のテンプレートが先頭、以降は名前順）
+func (s *Store) List() ([]Pack, error) {
+	packs := []Pack{{Name: DefaultPack, Builtin: true}}
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return packs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || !ValidName(e.Name()) || e.Name() == DefaultPack {
+			continue
+		}
+		pack, err := readMeta(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		packs = append(packs, pack)
+	}
+	saved := packs[1:]
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Name < saved[j].Name })
+	return packs, nil
+}
+
+func readMeta(dir string) (Pack, error) {
+	var pack Pack
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return pack, ErrNotFound
+	}
+	if err != nil {
+		return pack, fmt.Errorf("テンプレートパックの読み込み失敗: %v", err)
+	}
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return pack, fmt.Errorf("テンプレートパックの解析失敗: %v", err)
+	}
+	pack.Name = filepath.Base(dir)
+	return pack, nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの読み込み失敗: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートのデコード失敗: %v", err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("テンプレートの書き込み失敗: %v", err)
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// 画素データを持たず、ヘッダー (IHDR) だけで巨大なサイズを名乗るPNG
+func headerOnlyPNG(w, h uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	ihdr[8] = 8 // ビット深度
+	ihdr[9] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string // エラーメッセージに含まれる文字列（空なら成功）
+	}{
+		{name: "valid", data: encodePNG(t, 64, 32)},
+		{name: "too small", data: encodePNG(t, 4, 32), want: "小さすぎます"},
+		{name: "too large", data: encodePNG(t, MaxTemplateWidth+1, 8), want: "大きすぎます"},
+		{name: "huge header", data: headerOnlyPNG(100000, 100000), want: "大きすぎます"},
+		{name: "not png", data: []byte("not a png"), want: "PNGとして読み込めません"},
+		{name: "truncated", data: encodePNG(t, 64, 32)[:60], want: "PNGとして読み込めません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(bytes.NewReader(tt.data))
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 32 {
+					t.Errorf("bounds = %v; want 64x32", b)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v; want %q", err, tt.want)
+			}
+		})
+	}
+}
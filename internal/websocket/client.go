@@ -2,89 +2,311 @@ package websocket
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"lol-auto-accept/internal/logging"
 )
 
+func logger() *slog.Logger {
+	return logging.For(logging.ComponentServer)
+}
+
 type Manager struct {
-	clients      map[*websocket.Conn]bool
+	clients      map[*websocket.Conn]*client
 	clientsMutex sync.RWMutex
 	upgrader     websocket.Upgrader
+
+	// 履歴への追加と送信、再送とブロードキャスト開始を同じ順序で行うためのロック
+	// （clientsMutex より先に取る）
+	history      *history
+	historyMutex sync.Mutex
 }
 
 type LogMessage struct {
-	Type      string `json:"type"`
-	Message   string `json:"message"`
-	Timestamp string `json:"timestamp"`
+	Header
+	Level     string            `json:"level"` // debug / info / warn / error
+	Component string            `json:"component,omitempty"`
+	Message   string            `json:"message"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	Timestamp string            `json:"timestamp"`
 }
 
 type StatusUpdate struct {
-	Type   string `json:"type"`
+	Header
 	Status string `json:"status"`
 }
 
+type RateUpdate struct {
+	Header
+	IntervalMs int64  `json:"interval_ms"`
+	Mode       string `json:"mode"`
+}
+
+type StatsUpdate struct {
+	Header
+	FramesProcessed uint64 `json:"frames_processed"`
+	FramesSkipped   uint64 `json:"frames_skipped"`
+}
+
+type ModeUpdate struct {
+	Header
+	DryRun bool `json:"dry_run"`
+}
+
+type ClickEvent struct {
+	Header
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	DryRun    bool   `json:"dry_run"`
+	Timestamp string `json:"timestamp"`
+}
+
 func NewManager() *Manager {
 	return &Manager{
-		clients: make(map[*websocket.Conn]bool),
+		clients: make(map[*websocket.Conn]*client),
+		history: newHistory(historySize),
 		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			CheckOrigin: SameOrigin,
 		},
 	}
 }
 
+// Origin ヘッダーがない（ブラウザ以外からの）リクエストか、同じホストのページからのリクエストか
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
 func (m *Manager) HandleConnection(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
 	conn, err := m.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		logger().Warn("WebSocket接続のアップグレード失敗", "error", err)
 		return nil, err
 	}
 
+	c := newClient(conn)
+	c.startKeepalive()
+
 	m.clientsMutex.Lock()
-	m.clients[conn] = true
+	m.clients[conn] = c
 	m.clientsMutex.Unlock()
 
+	go c.writePump(func() { m.RemoveConnection(conn) })
 	return conn, nil
 }
 
+// クライアントを登録から外し、書き込み用のゴルーチンを止める（何度呼んでもよい）
 func (m *Manager) RemoveConnection(conn *websocket.Conn) {
 	m.clientsMutex.Lock()
-	defer m.clientsMutex.Unlock()
+	c, ok := m.clients[conn]
 	delete(m.clients, conn)
+	m.clientsMutex.Unlock()
+
+	if ok {
+		c.close()
+	}
 }
 
-func (m *Manager) BroadcastMessage(message interface{}) {
+// 接続中のクライアント数
+func (m *Manager) ClientCount() int {
 	m.clientsMutex.RLock()
 	defer m.clientsMutex.RUnlock()
+	return len(m.clients)
+}
+
+// 全クライアントの送信待ちに積む（受信が追いつかないクライアントは切断する）
+func (m *Manager) BroadcastMessage(message interface{}) {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		logger().Error("WebSocketメッセージの変換失敗", "error", err)
+		return
+	}
+	m.evict(m.broadcast(jsonData))
+}
+
+// 通し番号を付けて履歴に残し、全クライアントに送信
+// ログは WebSocket にも送られるため、ログの出力はロックを外してから行う
+func (m *Manager) publish(message event) {
+	slow, err := m.record(message)
+	if err != nil {
+		logger().Error("WebSocketメッセージの変換失敗", "error", err)
+		return
+	}
+	m.evict(slow)
+}
+
+func (m *Manager) record(message event) ([]*websocket.Conn, error) {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	seq := m.history.nextSeq()
+	message.setSeq(seq)
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	m.history.add(seq, jsonData)
+	return m.broadcast(jsonData), nil
+}
+
+// 接続時の状態を送った後に呼ぶ
+// since より後の履歴を再送し、以降のブロードキャストを受け取るようにする
+func (m *Manager) Replay(conn *websocket.Conn, since uint64) int {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	m.clientsMutex.Lock()
+	c, ok := m.clients[conn]
+	if ok {
+		c.live = true
+	}
+	m.clientsMutex.Unlock()
+	if !ok {
+		return 0
+	}
 
-	jsonData, _ := json.Marshal(message)
-	for client := range m.clients {
-		err := client.WriteMessage(websocket.TextMessage, jsonData)
-		if err != nil {
-			client.Close()
-			delete(m.clients, client)
+	entries := m.history.since(since)
+	for _, data := range entries {
+		if !c.enqueue(data) {
+			m.RemoveConnection(conn)
+			return 0
 		}
 	}
+	return len(entries)
+}
+
+// 最後に付けた通し番号
+func (m *Manager) LastSeq() uint64 {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+	return m.history.seq
+}
+
+// 送信待ちに積み、あふれたクライアントを返す
+func (m *Manager) broadcast(jsonData []byte) []*websocket.Conn {
+	var slow []*websocket.Conn
+	m.clientsMutex.RLock()
+	for conn, c := range m.clients {
+		if !c.live {
+			continue
+		}
+		if !c.enqueue(jsonData) {
+			slow = append(slow, conn)
+		}
+	}
+	m.clientsMutex.RUnlock()
+	return slow
+}
+
+// 受信が追いつかないクライアントを切断する
+func (m *Manager) evict(slow []*websocket.Conn) {
+	for _, conn := range slow {
+		m.RemoveConnection(conn)
+		logger().Warn("送信待ちがあふれたためWebSocketクライアントを切断しました", "remote", conn.RemoteAddr().String())
+	}
 }
 
-func (m *Manager) SendLog(message string) {
+// 1つのクライアントにだけ送信（操作への応答や接続時の状態）
+func (m *Manager) Send(conn *websocket.Conn, message interface{}) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	m.clientsMutex.RLock()
+	c, ok := m.clients[conn]
+	m.clientsMutex.RUnlock()
+	if !ok {
+		return errClientGone
+	}
+	if !c.enqueue(jsonData) {
+		m.RemoveConnection(conn)
+		return errClientGone
+	}
+	return nil
+}
+
+// 構造化ログの1件を送信（logging.SetFeed に渡す）
+func (m *Manager) SendLog(entry logging.Entry) {
 	logMsg := LogMessage{
-		Type:      "log",
-		Message:   message,
-		Timestamp: time.Now().Format("15:04:05"),
+		Header:    header(TypeLog),
+		Level:     strings.ToLower(entry.Level.String()),
+		Component: entry.Component,
+		Message:   entry.Message,
+		Attrs:     entry.Attrs,
+		Timestamp: entry.Time.Format("15:04:05"),
 	}
-	m.BroadcastMessage(logMsg)
+	m.publish(&logMsg)
+}
+
+func NewStatusUpdate(status string) StatusUpdate {
+	return StatusUpdate{Header: header(TypeStatus), Status: status}
 }
 
 func (m *Manager) UpdateStatus(status string) {
-	statusMsg := StatusUpdate{
-		Type:   "status",
-		Status: status,
+	m.BroadcastMessage(NewStatusUpdate(status))
+}
+
+func (m *Manager) SendClick(x, y int, dryRun bool) {
+	clickMsg := ClickEvent{
+		Header:    header(TypeClick),
+		X:         x,
+		Y:         y,
+		DryRun:    dryRun,
+		Timestamp: time.Now().Format("15:04:05"),
 	}
-	m.BroadcastMessage(statusMsg)
-}
\ No newline at end of file
+	m.publish(&clickMsg)
+}
+
+func (m *Manager) UpdateState(state StateEvent) {
+	m.publish(&state)
+}
+
+func (m *Manager) SendDetection(target string, x, y int, score float64, elapsedMs int64) {
+	m.publish(&DetectionEvent{
+		Header:    header(TypeDetection),
+		Target:    target,
+		X:         x,
+		Y:         y,
+		Score:     score,
+		ElapsedMs: elapsedMs,
+	})
+}
+
+func NewModeUpdate(dryRun bool) ModeUpdate {
+	return ModeUpdate{Header: header(TypeMode), DryRun: dryRun}
+}
+
+func (m *Manager) UpdateMode(dryRun bool) {
+	m.BroadcastMessage(NewModeUpdate(dryRun))
+}
+
+func (m *Manager) UpdateStats(framesProcessed, framesSkipped uint64) {
+	statsMsg := StatsUpdate{
+		Header:          header(TypeStats),
+		FramesProcessed: framesProcessed,
+		FramesSkipped:   framesSkipped,
+	}
+	m.BroadcastMessage(statsMsg)
+}
+
+func NewRateUpdate(intervalMs int64, mode string) RateUpdate {
+	return RateUpdate{Header: header(TypeRate), IntervalMs: intervalMs, Mode: mode}
+}
+
+func (m *Manager) UpdateRate(intervalMs int64, mode string) {
+	m.BroadcastMessage(NewRateUpdate(intervalMs, mode))
+}
@@ -0,0 +1,232 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"lol-auto-accept/internal/logging"
+)
+
+// server.HandleWebSocket と同じ順序で登録・再送し、切断まで読み込む
+func newTestServer(t *testing.T, m *Manager) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := m.HandleConnection(w, r)
+		if err != nil {
+			return
+		}
+		since, _ := strconv.ParseUint(r.URL.Query().Get("since"), 10, 64)
+		m.Replay(conn, since)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				m.RemoveConnection(conn)
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// 受信したイベントの通し番号を記録するクライアント
+type testClient struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex
+	seqs  []uint64
+}
+
+func dial(t *testing.T, srv *httptest.Server, since uint64) *testClient {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?since=" + strconv.FormatUint(since, 10)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{conn: conn}
+}
+
+// 切断されるまで読み込む
+func (c *testClient) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var h Header
+		if err := json.Unmarshal(data, &h); err != nil || h.Seq == 0 {
+			continue
+		}
+		c.mutex.Lock()
+		c.seqs = append(c.seqs, h.Seq)
+		c.mutex.Unlock()
+	}
+}
+
+func (c *testClient) received() []uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]uint64(nil), c.seqs...)
+}
+
+// last までのイベントを受信するまで待つ
+func (c *testClient) waitFor(t *testing.T, last uint64) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		seqs := c.received()
+		if len(seqs) > 0 && seqs[len(seqs)-1] >= last {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d events up to %v; want up to seq %d", len(seqs), tail(seqs), last)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func tail(seqs []uint64) interface{} {
+	if len(seqs) == 0 {
+		return "none"
+	}
+	return seqs[len(seqs)-1]
+}
+
+// 通し番号が first から1ずつ増えていること（欠けや重複、順序の入れ替わりがないこと）
+func checkContiguous(t *testing.T, name string, seqs []uint64, first, last uint64) {
+	t.Helper()
+	if uint64(len(seqs)) != last-first+1 {
+		t.Errorf("%s received %d events; want %d (%d..%d)", name, len(seqs), last-first+1, first, last)
+	}
+	for i, seq := range seqs {
+		if seq != first+uint64(i) {
+			t.Errorf("%s seqs[%d] = %d; want %d", name, i, seq, first+uint64(i))
+			return
+		}
+	}
+}
+
+func TestConcurrentPublishKeepsOrder(t *testing.T) {
+	m := NewManager()
+	srv := newTestServer(t, m)
+
+	clients := make([]*testClient, 3)
+	for i := range clients {
+		clients[i] = dial(t, srv, 0)
+		go clients[i].readLoop()
+	}
+	// 全員がブロードキャストを受け取る状態になってから送る
+	m.SendDetection(TargetMatching, 0, 0, 0, 0)
+	for _, c := range clients {
+		c.waitFor(t, 1)
+	}
+
+	// 合計が送信待ちの上限を超えない範囲で、複数のゴルーチンから同時に送る
+	const publishers, perPublisher = 4, 50
+	var wg sync.WaitGroup
+	for p := 0; p < publishers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perPublisher; i++ {
+				if i%2 == 0 {
+					m.SendDetection(TargetAccept, p, i, 0.9, 1)
+				} else {
+					m.SendClick(p, i, true)
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	last := m.LastSeq()
+	if last != 1+publishers*perPublisher {
+		t.Fatalf("LastSeq = %d; want %d", last, 1+publishers*perPublisher)
+	}
+	for i, c := range clients {
+		c.waitFor(t, last)
+		checkContiguous(t, "client "+strconv.Itoa(i), c.received(), 1, last)
+	}
+	if n := m.ClientCount(); n != len(clients) {
+		t.Errorf("ClientCount = %d; want %d", n, len(clients))
+	}
+}
+
+func TestSlowClientEvicted(t *testing.T) {
+	m := NewManager()
+	srv := newTestServer(t, m)
+
+	fast := dial(t, srv, 0)
+	go fast.readLoop()
+	slow := dial(t, srv, 0)
+
+	m.SendDetection(TargetMatching, 0, 0, 0, 0)
+	fast.waitFor(t, 1)
+	// 読み込まないクライアントも登録が済むまで待つ
+	slow.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if _, _, err := slow.conn.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 大きなログを送り続けると、読み込まないクライアントはソケットと送信待ちがあふれて切断される
+	// 読み込むクライアントは毎回追いつくのを待つため切断されない
+	payload := strings.Repeat("x", 64<<10)
+	const batch = 64
+	deadline := time.Now().Add(30 * time.Second)
+	for m.ClientCount() > 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("slow client was not evicted")
+		}
+		for i := 0; i < batch; i++ {
+			m.SendLog(logging.Entry{Time: time.Now(), Message: payload})
+		}
+		fast.waitFor(t, m.LastSeq())
+	}
+
+	checkContiguous(t, "fast client", fast.received(), 1, m.LastSeq())
+
+	// 切断されたクライアントには以降のイベントを送らない
+	before := m.LastSeq()
+	m.SendClick(1, 2, false)
+	fast.waitFor(t, before+1)
+	if n := m.ClientCount(); n != 1 {
+		t.Errorf("ClientCount = %d; want 1", n)
+	}
+}
+
+func TestReplayWhilePublishing(t *testing.T) {
+	m := NewManager()
+	srv := newTestServer(t, m)
+
+	for i := 0; i < 50; i++ {
+		m.SendClick(i, i, true)
+	}
+
+	// 再接続の途中にも送り続ける（再送とブロードキャストの間で欠けたり重複したりしない）
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m.SendDetection(TargetAccept, i, i, 0.9, 1)
+		}
+	}()
+	resumed := dial(t, srv, 20)
+	go resumed.readLoop()
+	// サーバーの知らない番号（再起動前の番号）からの再接続は履歴をすべて受け取る
+	restarted := dial(t, srv, 1000)
+	go restarted.readLoop()
+	<-done
+
+	last := m.LastSeq()
+	resumed.waitFor(t, last)
+	restarted.waitFor(t, last)
+	checkContiguous(t, "resumed client", resumed.received(), 21, last)
+	checkContiguous(t, "restarted client", restarted.received(), 1, last)
+}
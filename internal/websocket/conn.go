@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 1回の書き込みの制限時間
+	writeWait = 10 * time.Second
+	// この時間 pong（または他のメッセージ）が届かなければ切断する
+	pongWait = 60 * time.Second
+	// ping を送る間隔（pongWait より短くする）
+	pingPeriod = pongWait * 9 / 10
+	// クライアントごとの送信待ちメッセージ数の上限（超えたクライアントは切断する）
+	sendBufferSize = 256
+)
+
+var errClientGone = errors.New("WebSocketクライアントは切断されています")
+
+// 接続中のクライアント
+// 接続への書き込みは writePump だけが行い、他のゴルーチンは send に積むだけにする
+type client struct {
+	conn      *websocket.Conn
+	live      bool // 履歴の再送が済み、ブロードキャストを受け取る
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(conn *websocket.Conn) *client {
+	return &client{
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+		done: make(chan struct{}),
+	}
+}
+
+// 送信待ちに積む（切断済み、または送信待ちがいっぱいの場合は false）
+func (c *client) enqueue(data []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// 読み込み側の設定（pong が届くたびに期限を延ばす）
+func (c *client) startKeepalive() {
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+}
+
+// 送信待ちのメッセージと ping を書き込む
+// 終了時に接続を閉じるため、読み込み側のループもエラーで抜ける
+func (c *client) writePump(onError func()) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				onError()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				onError()
+				return
+			}
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
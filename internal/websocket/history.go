@@ -0,0 +1,57 @@
+package websocket
+
+// 新しく接続したクライアントに再送するため、直近のイベントを保持する
+// 件数は送信待ちの上限より小さくしておく（再送だけで切断されないように）
+const historySize = 200
+
+type historyEntry struct {
+	seq  uint64
+	data []byte
+}
+
+// 直近のイベントのリングバッファ（排他は Manager 側で行う）
+type history struct {
+	entries []historyEntry
+	next    int
+	full    bool
+	seq     uint64
+}
+
+func newHistory(size int) *history {
+	return &history{entries: make([]historyEntry, size)}
+}
+
+// 次の通し番号（1から始まる）
+func (h *history) nextSeq() uint64 {
+	h.seq++
+	return h.seq
+}
+
+func (h *history) add(seq uint64, data []byte) {
+	h.entries[h.next] = historyEntry{seq: seq, data: data}
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since より後のイベントを古い順に返す
+// since がサーバーの知らない番号の場合（サーバーの再起動後など）はすべて返す
+func (h *history) since(since uint64) [][]byte {
+	if since > h.seq {
+		since = 0
+	}
+	start, count := 0, h.next
+	if h.full {
+		start, count = h.next, len(h.entries)
+	}
+
+	var result [][]byte
+	for i := 0; i < count; i++ {
+		e := h.entries[(start+i)%len(h.entries)]
+		if e.seq > since {
+			result = append(result, e.data)
+		}
+	}
+	return result
+}
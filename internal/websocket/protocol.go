@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// プロトコルのバージョン（互換性のない変更を加えたら上げる）
+const ProtocolVersion = 1
+
+// クライアントから送れる操作
+const (
+	ActionStart = "start" // 監視開始
+	ActionStop  = "stop"  // 監視停止
+	ActionTest  = "test"  // 環境テスト
+)
+
+var Actions = []string{ActionStart, ActionStop, ActionTest}
+
+// サーバーから送るメッセージの種類
+const (
+	TypeHello     = "hello"     // 接続直後に1回（プロトコルのバージョンと操作の一覧）
+	TypeAck       = "ack"       // 操作を受け付けた
+	TypeError     = "error"     // 操作を受け付けなかった
+	TypeLog       = "log"       // ログ
+	TypeStatus    = "status"    // 状態の表示用テキスト
+	TypeState     = "state"     // 状態の変化
+	TypeDetection = "detection" // 検出結果
+	TypeClick     = "click"     // クリック
+	TypeStats     = "stats"     // フレーム統計
+	TypeRate      = "rate"      // ポーリング間隔
+	TypeMode      = "mode"      // ドライランかどうか
+)
+
+// エラーの種類
+const (
+	ErrorBadRequest         = "bad_request"
+	ErrorUnsupportedVersion = "unsupported_version"
+	ErrorUnknownAction      = "unknown_action"
+	ErrorFailed             = "failed" // 操作を実行したが失敗した
+)
+
+// 全メッセージ共通の項目
+// seq は履歴に残るイベント（log / click / detection / state）の通し番号で、再接続時の since に使う
+type Header struct {
+	V    int    `json:"v"`
+	Type string `json:"type"`
+	Seq  uint64 `json:"seq,omitempty"`
+}
+
+func (h *Header) setSeq(seq uint64) {
+	h.Seq = seq
+}
+
+// 履歴に残すメッセージ
+type event interface {
+	setSeq(seq uint64)
+}
+
+func header(messageType string) Header {
+	return Header{V: ProtocolVersion, Type: messageType}
+}
+
+// クライアントからの操作
+// v を省略した場合は現在のバージョンとして扱う、id は応答にそのまま返す
+type Command struct {
+	V      int    `json:"v"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// 受け付けられなかった操作
+type ProtocolError struct {
+	ID      string
+	Code    string
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// 操作の実行に失敗した
+func CommandFailed(cmd Command, err error) *ProtocolError {
+	return &ProtocolError{ID: cmd.ID, Code: ErrorFailed, Message: err.Error()}
+}
+
+// 受信したメッセージを操作として解釈する（不正な内容でもパニックしない）
+func ParseCommand(data []byte) (Command, *ProtocolError) {
+	var cmd Command
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return cmd, &ProtocolError{Code: ErrorBadRequest, Message: fmt.Sprintf("JSONとして解釈できません: %v", err)}
+	}
+	if cmd.V != 0 && cmd.V != ProtocolVersion {
+		return cmd, &ProtocolError{ID: cmd.ID, Code: ErrorUnsupportedVersion,
+			Message: fmt.Sprintf("プロトコルのバージョン %d には対応していません (対応: %d)", cmd.V, ProtocolVersion)}
+	}
+	for _, action := range Actions {
+		if cmd.Action == action {
+			return cmd, nil
+		}
+	}
+	return cmd, &ProtocolError{ID: cmd.ID, Code: ErrorUnknownAction, Message: fmt.Sprintf("不明な操作です: %q", cmd.Action)}
+}
+
+type HelloMessage struct {
+	Header
+	Protocol int      `json:"protocol"`
+	Actions  []string `json:"actions"`
+	LastSeq  uint64   `json:"last_seq"` // サーバーが最後に付けた通し番号（これより大きい since は再起動前のもの）
+}
+
+type AckMessage struct {
+	Header
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+type ErrorMessage struct {
+	Header
+	ID      string `json:"id"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 監視の状態
+type StateEvent struct {
+	Header
+	Running         bool   `json:"running"`           // 監視中
+	WaitingForMatch bool   `json:"waiting_for_match"` // マッチング画面待ち（false の場合は承認ボタン監視中）
+	AutoWatching    bool   `json:"auto_watching"`     // 自動監視が有効
+	Status          string `json:"status"`            // 表示用テキスト
+}
+
+// 検出結果（target は "matching" または "accept"）
+type DetectionEvent struct {
+	Header
+	Target    string  `json:"target"`
+	X         int     `json:"x,omitempty"`
+	Y         int     `json:"y,omitempty"`
+	Score     float64 `json:"score,omitempty"`
+	ElapsedMs int64   `json:"elapsed_ms"`
+}
+
+// 検出対象
+const (
+	TargetMatching = "matching"
+	TargetAccept   = "accept"
+)
+
+func NewHello(lastSeq uint64) HelloMessage {
+	return HelloMessage{Header: header(TypeHello), Protocol: ProtocolVersion, Actions: Actions, LastSeq: lastSeq}
+}
+
+func NewState(running, waitingForMatch, autoWatching bool, status string) StateEvent {
+	return StateEvent{
+		Header:          header(TypeState),
+		Running:         running,
+		WaitingForMatch: waitingForMatch,
+		AutoWatching:    autoWatching,
+		Status:          status,
+	}
+}
+
+func NewAck(cmd Command) AckMessage {
+	return AckMessage{Header: header(TypeAck), ID: cmd.ID, Action: cmd.Action}
+}
+
+func NewError(err *ProtocolError) ErrorMessage {
+	return ErrorMessage{Header: header(TypeError), ID: err.ID, Code: err.Code, Message: err.Message}
+}
@@ -0,0 +1,92 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		action string
+		code   string // 空の場合は受け付ける
+		id     string
+	}{
+		{name: "valid", data: `{"v":1,"id":"7","action":"start"}`, action: ActionStart, id: "7"},
+		{name: "version omitted", data: `{"id":"8","action":"test"}`, action: ActionTest, id: "8"},
+		{name: "malformed json", data: `{"v":1,"action":`, code: ErrorBadRequest},
+		{name: "not an object", data: `"start"`, code: ErrorBadRequest},
+		{name: "wrong field type", data: `{"v":"1","action":"start"}`, code: ErrorBadRequest},
+		{name: "unknown action", data: `{"v":1,"id":"9","action":"explode"}`, code: ErrorUnknownAction, id: "9"},
+		{name: "missing action", data: `{"v":1,"id":"10"}`, code: ErrorUnknownAction, id: "10"},
+		{name: "unsupported version", data: `{"v":2,"id":"11","action":"start"}`, code: ErrorUnsupportedVersion, id: "11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseCommand([]byte(tt.data))
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cmd.Action != tt.action || cmd.ID != tt.id {
+					t.Fatalf("cmd = %+v; want action %q id %q", cmd, tt.action, tt.id)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("accepted %s; want %s", tt.data, tt.code)
+			}
+			if err.Code != tt.code || err.ID != tt.id {
+				t.Fatalf("error = %+v; want code %q id %q", err, tt.code, tt.id)
+			}
+		})
+	}
+}
+
+// JSON に変換したメッセージを汎用の map として読み直す
+func envelope(t *testing.T, message interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAckEnvelope(t *testing.T) {
+	m := envelope(t, NewAck(Command{V: ProtocolVersion, ID: "1", Action: ActionStop}))
+	want := map[string]interface{}{"v": float64(ProtocolVersion), "type": TypeAck, "id": "1", "action": ActionStop}
+	if len(m) != len(want) {
+		t.Fatalf("ack = %v; want %v", m, want)
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("ack[%q] = %v; want %v", k, m[k], v)
+		}
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	_, perr := ParseCommand([]byte(`{"v":1,"id":"2","action":"nope"}`))
+	m := envelope(t, NewError(perr))
+	if m["v"] != float64(ProtocolVersion) || m["type"] != TypeError || m["id"] != "2" || m["code"] != ErrorUnknownAction {
+		t.Errorf("error = %v", m)
+	}
+	if msg, _ := m["message"].(string); msg == "" {
+		t.Errorf("error message is empty: %v", m)
+	}
+	if _, ok := m["seq"]; ok {
+		t.Errorf("error has seq: %v", m)
+	}
+
+	m = envelope(t, NewError(CommandFailed(Command{ID: "3", Action: ActionStart}, errors.New("template missing"))))
+	if m["type"] != TypeError || m["id"] != "3" || m["code"] != ErrorFailed || m["message"] != "template missing" {
+		t.Errorf("failure = %v", m)
+	}
+}
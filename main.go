@@ -1,23 +1,110 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"os"
 
 	"lol-auto-accept/internal/app"
+	"lol-auto-accept/internal/config"
+	"lol-auto-accept/internal/logging"
 	"lol-auto-accept/internal/server"
 )
 
 func main() {
+	configPath := flag.String("config", config.DefaultPath, "設定ファイルのパス")
+	dryRun := flag.Bool("dry-run", false, "マウスを操作せずクリック予定位置のみ記録する")
+	debug := flag.Bool("debug", false, "判定ごとに注釈付きスナップショットを保存する")
+	useTLS := flag.Bool("tls", false, "操作用サーバーをHTTPSで起動する（証明書がない場合は自己署名証明書を生成）")
+	addr := flag.String("addr", "", "操作用サーバーの待ち受けアドレス（例: 127.0.0.1:8081、省略時は設定ファイルの値）")
+	flag.Parse()
+
+	// 設定読み込み
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *dryRun {
+		cfg.DryRun = true
+	}
+	if *debug {
+		cfg.Debug.Snapshots = true
+	}
+	if *addr != "" {
+		cfg.Server.Address = *addr
+	}
+	if *useTLS {
+		cfg.Server.TLS = true
+	}
+
+	// ログ出力の設定（標準出力・ファイル・Web UI）
+	level, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logFile, err := logging.Setup(logging.Options{
+		Level:    level,
+		File:     cfg.Log.File,
+		MaxBytes: int64(cfg.Log.MaxSizeMB) * 1024 * 1024,
+		MaxFiles: cfg.Log.MaxFiles,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger := logging.For(logging.ComponentApp)
+
+	// 操作用サーバーのトークン（初回起動時に生成して設定ファイルに保存）
+	if cfg.Server.Token == "" {
+		token, err := config.GenerateToken()
+		if err != nil {
+			logger.Error("トークンを生成できませんでした", "error", err)
+			logFile.Close()
+			os.Exit(1)
+		}
+		cfg.Server.Token = token
+		err = config.Update(*configPath, func(c *config.Config) {
+			c.Server.Token = token
+		})
+		if err != nil {
+			logger.Warn("トークンを設定ファイルに保存できませんでした（次回の起動時は別のトークンになります）", "error", err)
+		}
+	}
+
 	// アプリケーションインスタンス作成
-	application := app.NewApp()
+	application := app.NewApp(cfg)
+	application.SetConfigPath(*configPath)
 	
 	// サーバーインスタンス作成
-	srv := server.NewServer(application)
+	srv := server.NewServer(application, cfg.Server)
+	if cfg.Server.TLS {
+		if err := srv.LoadCertificate(); err != nil {
+			logger.Error("証明書を読み込めませんでした", "error", err)
+			logFile.Close()
+			os.Exit(1)
+		}
+		logger.Info("HTTPSで待ち受けます（ブラウザの警告画面で証明書のフィンガープリントが一致することを確認してください）",
+			"fingerprint", srv.Fingerprint())
+	}
 	
-	log.Println("LoL Auto Accept アプリを起動中...")
-	log.Println("サーバー起動: http://localhost:8081")
-	log.Println("最適化済み: 高速検出アルゴリズム搭載")
+	logger.Info("LoL Auto Accept アプリを起動中...")
+	logger.Info("サーバー起動", "address", cfg.Server.Address)
+	if host, _, err := net.SplitHostPort(cfg.Server.Address); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			logger.Warn("他の端末からアクセスできるアドレスで待ち受けます", "address", cfg.Server.Address)
+		}
+	}
+	// トークンはログファイルに残さないよう端末にだけ表示する
+	fmt.Printf("ブラウザで開くURL（トークン付き）: %s\n", srv.URL())
+	logger.Info("最適化済み: 高速検出アルゴリズム搭載")
+	if cfg.DryRun {
+		logger.Info("ドライランモード: マウス操作は行いません")
+	}
 	
 	// サーバー開始
-	log.Fatal(srv.Start())
+	err = srv.Start()
+	logger.Error("サーバーが終了しました", "error", err)
+	logFile.Close()
+	os.Exit(1)
 }
\ No newline at end of file